@@ -1,19 +1,44 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 func newCheckCmd(cfg *config) *cobra.Command {
 	var availableOnly bool
 	var only string
 	var sortBy string
+	var inputFormat string
+	var minConfidence string
+	var keepDuplicates bool
+	var csvColumn string
+	var requireResults bool
+	var baseDomain bool
+	var allowEmpty bool
+	var groupBy string
+	var normalizeOnly bool
+	var stripWWW bool
+	var resumePath string
+	var strictTLDs bool
+	var allowUnknownTLDs bool
+	var ignoreInputErrors bool
+	var quietErrs bool
+	var tldsFromStdin bool
+	var webhookURL string
+	var webhookOn string
+	var limitAvailable int
+	statusFiles := make(map[availability.Status]*string)
 
 	cmd := &cobra.Command{
 		Use:   "check [domain...]",
@@ -22,14 +47,94 @@ func newCheckCmd(cfg *config) *cobra.Command {
 dothuntcli check openai.com example.com
 printf "openai.com\nexample.com\n" | dothuntcli --ndjson check
 dothuntcli --format json --registrar none check example.com
+cat tlds.txt | dothuntcli check --tlds-from-stdin foo bar
 `),
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			inputDomains, err := readDomainsFromArgsAndStdin(args, os.Stdin)
+			var readStdin func(io.Reader) ([]string, error)
+			// scores holds "|score" annotations from stdin, by normalized domain.
+			scores := make(map[string]int)
+			switch strings.ToLower(strings.TrimSpace(inputFormat)) {
+			case "", "lines":
+				readStdin = func(r io.Reader) ([]string, error) {
+					lines, err := domain.ReadLinesScored(r)
+					if err != nil {
+						return nil, err
+					}
+					values := make([]string, len(lines))
+					for i, l := range lines {
+						values[i] = l.Domain
+						if ascii, err := domain.NormalizeWithProfile(l.Domain, cfg.idnaProfile); err == nil && l.Scored {
+							scores[ascii] = l.Score
+						}
+					}
+					return values, nil
+				}
+			case "csv":
+				readStdin = func(r io.Reader) ([]string, error) {
+					values, rowErrs, err := domain.ReadCSVColumn(r, csvColumn)
+					if !cfg.Quiet {
+						for _, rowErr := range rowErrs {
+							fmt.Fprintf(os.Stderr, "skipping malformed CSV row: %v\n", rowErr)
+						}
+					}
+					return values, err
+				}
+			default:
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --input-format %q (use lines|csv)", inputFormat), ShowUsage: true, Cmd: cmd}
+			}
+
+			var webhookPred func(availability.Result) bool
+			if webhookURL = strings.TrimSpace(webhookURL); webhookURL != "" {
+				if err := validateWebhookURL(webhookURL); err != nil {
+					return &cliError{Code: 2, Err: err, ShowUsage: true, Cmd: cmd}
+				}
+				pred, err := parseWebhookOn(webhookOn)
+				if err != nil {
+					return &cliError{Code: 2, Err: err, ShowUsage: true, Cmd: cmd}
+				}
+				webhookPred = pred
+			}
+
+			readInputs := func() ([]string, error) {
+				return readDomainsFromArgsAndStdin(args, os.Stdin, readStdin)
+			}
+			if tldsFromStdin {
+				if strings.ToLower(strings.TrimSpace(inputFormat)) == "csv" {
+					return &cliError{Code: 2, Err: fmt.Errorf("--tlds-from-stdin reads one TLD per line; drop --input-format csv"), ShowUsage: true, Cmd: cmd}
+				}
+				if term.IsTerminal(int(os.Stdin.Fd())) {
+					return &cliError{Code: 2, Err: fmt.Errorf("--tlds-from-stdin: pipe TLDs on stdin, e.g. cat tlds.txt | dothuntcli check --tlds-from-stdin foo"), ShowUsage: true, Cmd: cmd}
+				}
+				var labels []string
+				for _, a := range args {
+					if a = strings.TrimSpace(a); a == "" {
+						continue
+					}
+					if strings.Contains(a, ".") {
+						return &cliError{Code: 2, Err: fmt.Errorf("--tlds-from-stdin takes labels as args, not domains (got %q)", a), ShowUsage: true, Cmd: cmd}
+					}
+					labels = append(labels, a)
+				}
+				if len(labels) == 0 {
+					return &cliError{Code: 2, Err: fmt.Errorf("--tlds-from-stdin needs at least one label as an arg"), ShowUsage: true, Cmd: cmd}
+				}
+				readInputs = func() ([]string, error) {
+					tlds, err := readTLDList(os.Stdin)
+					if err != nil {
+						return nil, err
+					}
+					return joinLabelsAndTLDs(labels, tlds), nil
+				}
+			}
+			inputDomains, err := readInputs()
 			if err != nil {
 				return &cliError{Code: 1, Err: fmt.Errorf("failed to read domains: %w", err), Cmd: cmd}
 			}
 			if len(inputDomains) == 0 {
+				if allowEmpty {
+					return nil
+				}
 				return &cliError{
 					Code:      2,
 					Err:       fmt.Errorf("missing domains; pass domains as args or pipe newline-delimited domains on stdin"),
@@ -38,18 +143,90 @@ dothuntcli --format json --registrar none check example.com
 				}
 			}
 
-			results := cfg.checker.CheckDomains(cmd.Context(), inputDomains)
+			if stripWWW {
+				inputDomains = stripWWWInputs(inputDomains, cfg.idnaProfile, func(in, stripped string) {
+					if !cfg.Quiet {
+						fmt.Fprintf(os.Stderr, "Stripped www. from %s; checking %s\n", strings.TrimSpace(in), stripped)
+					}
+				})
+			}
 
-			enrichWithRegistrar(cmd.Context(), cfg.registrar, cfg.RegistrarConcurrency, results, func(r availability.Result) bool {
-				return r.Status == availability.StatusAvailable || r.Status == availability.StatusUnknown
-			})
+			if baseDomain {
+				inputDomains = reduceToBaseDomains(inputDomains, cfg.idnaProfile, func(in, base string) {
+					if !cfg.Quiet {
+						fmt.Fprintf(os.Stderr, "Reduced %s to registrable domain %s\n", strings.TrimSpace(in), base)
+					}
+				})
+			}
 
-			strictFail := false
-			if cfg.Strict {
-				for _, r := range results {
-					if r.Status == availability.StatusUnknown || r.Error != "" {
-						strictFail = true
-						break
+			if !keepDuplicates {
+				var dropped int
+				inputDomains, dropped = dedupeDomains(inputDomains, cfg.idnaProfile)
+				if dropped > 0 && cfg.Verbose && !cfg.Quiet {
+					fmt.Fprintf(os.Stderr, "Collapsed %d duplicate domain(s)\n", dropped)
+				}
+			}
+
+			if normalizeOnly {
+				invalid := 0
+				for _, in := range inputDomains {
+					ascii, err := domain.NormalizeWithProfile(in, cfg.idnaProfile)
+					if err != nil {
+						invalid++
+						if !cfg.Quiet {
+							fmt.Fprintf(os.Stderr, "invalid domain %q: %v\n", strings.TrimSpace(in), err)
+						}
+						continue
+					}
+					if _, err := fmt.Fprintln(os.Stdout, ascii); err != nil {
+						return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+					}
+				}
+				if cfg.Strict && invalid > 0 && !ignoreInputErrors {
+					return &cliError{Code: 1}
+				}
+				return nil
+			}
+
+			// Inputs under undelegated TLDs aren't looked up, but still get
+			// an unknown row.
+			var skippedResults []availability.Result
+			if unlisted := unlistedTLDs(inputDomains, cfg.idnaProfile); len(unlisted) > 0 {
+				// Typos like .con would otherwise just come back unknown.
+				known := knownTLDs(cmd.Context(), cfg)
+				candidates, bootstrapErr := cfg.rdapClient.TLDs(cmd.Context())
+				var unknown []string
+				var msgs []string
+				for _, tld := range unlisted {
+					if !known[tld] {
+						unknown = append(unknown, tld)
+						msgs = append(msgs, unknownTLDMessage(tld, candidates))
+					}
+				}
+				if len(msgs) > 0 && strictTLDs {
+					return &cliError{Code: 2, Err: fmt.Errorf("%s", strings.Join(msgs, "; ")), ShowUsage: true, Cmd: cmd}
+				}
+				// Absent from both the public suffix list and the bootstrap
+				// means retired or never delegated, so every lookup would be
+				// wasted. Without a bootstrap there's no telling a TLD newer
+				// than the embedded list, so only warn.
+				dropped := map[string]int{}
+				if len(unknown) > 0 && !allowUnknownTLDs && bootstrapErr == nil {
+					skip := make(map[string]bool, len(unknown))
+					for _, tld := range unknown {
+						skip[tld] = true
+					}
+					inputDomains, skippedResults = dropTLDs(inputDomains, cfg.idnaProfile, skip)
+					for _, r := range skippedResults {
+						dropped[r.TLD]++
+					}
+				}
+				if !cfg.Quiet {
+					for i, msg := range msgs {
+						if n := dropped[unknown[i]]; n > 0 {
+							msg += fmt.Sprintf("; skipping %d domain(s) (--allow-unknown-tlds checks them anyway)", n)
+						}
+						fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
 					}
 				}
 			}
@@ -62,14 +239,158 @@ dothuntcli --format json --registrar none check example.com
 				onlyVal = "available"
 			}
 			switch onlyVal {
-			case "all":
-			case "available", "taken", "unknown":
-			case "buyable":
+			case "all", "available", "taken", "reserved", "unknown", "dropping":
+			case "buyable", "premium", "nonpremium":
 				if cfg.registrar == nil {
-					return &cliError{Code: 2, Err: fmt.Errorf("--only buyable requires --registrar (or PORKBUN_API_KEY/PORKBUN_SECRET_API_KEY)"), ShowUsage: true, Cmd: cmd}
+					return &cliError{Code: 2, Err: fmt.Errorf("--only %s requires --registrar (or PORKBUN_API_KEY/PORKBUN_SECRET_API_KEY or NAMECOM_USERNAME/NAMECOM_TOKEN)", onlyVal), ShowUsage: true, Cmd: cmd}
 				}
 			default:
-				return &cliError{Code: 2, Err: fmt.Errorf("invalid --only %q (use all|available|taken|unknown|buyable)", only), ShowUsage: true, Cmd: cmd}
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --only %q (use all|available|taken|reserved|unknown|dropping|buyable|premium|nonpremium)", only), ShowUsage: true, Cmd: cmd}
+			}
+
+			if limitAvailable < 0 {
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --limit-available %d (must be >= 0)", limitAvailable), ShowUsage: true, Cmd: cmd}
+			}
+			// --limit-available cancels the remaining lookups once enough
+			// available domains have come in.
+			ctx, stop := context.WithCancel(cmd.Context())
+			defer stop()
+
+			var record func(availability.Result)
+			if path := strings.TrimSpace(resumePath); path != "" {
+				done, err := readResumeState(path)
+				if err != nil {
+					return &cliError{Code: 1, Err: fmt.Errorf("failed to read --resume state: %w", err), Cmd: cmd}
+				}
+				var skipped int
+				inputDomains, skipped = skipResumed(inputDomains, cfg.idnaProfile, done)
+				if skipped > 0 && !cfg.Quiet {
+					fmt.Fprintf(os.Stderr, "Resuming from %s: skipping %d already-checked domain(s)\n", path, skipped)
+				}
+				log, err := openResumeLog(path)
+				if err != nil {
+					return &cliError{Code: 1, Err: fmt.Errorf("failed to open --resume state: %w", err), Cmd: cmd}
+				}
+				defer func() {
+					if err := log.Close(); err != nil && !cfg.Quiet {
+						fmt.Fprintf(os.Stderr, "Could not write --resume state: %v\n", err)
+					}
+				}()
+				record = func(r availability.Result) { log.record(ctx, r) }
+			}
+
+			var webhook *webhookPoster
+			if webhookPred != nil {
+				webhook = newWebhookPoster(cmd.Context(), webhookURL, cfg.httpClient, cfg.Timeout)
+			}
+			// A result is final once the registrar (if any) has priced it;
+			// only then does it go to the --resume state and the webhook.
+			finished := func(r availability.Result) {
+				if record != nil {
+					record(r)
+				}
+				if webhook != nil && webhookPred(r) {
+					webhook.enqueue(r)
+				}
+			}
+			var enrich *registrarStream
+			if cfg.registrar != nil {
+				enrich = startRegistrarStream(cmd.Context(), cfg.registrar, enrichOptions{
+					concurrency: cfg.RegistrarConcurrency,
+					limiter:     cfg.registrarLimiter,
+					cache:       cfg.registrarCache,
+					batchSize:   cfg.RegistrarBatchSize,
+				}, func(r availability.Result) bool {
+					return r.Status == availability.StatusAvailable || r.Status == availability.StatusUnknown
+				}, finished)
+			}
+			var found []availability.Result
+			results, checkErr := cfg.checker.CheckDomainsFunc(ctx, inputDomains, func(r availability.Result) {
+				switch {
+				case ctx.Err() != nil && r.Status == availability.StatusUnknown:
+					// Canceled by --limit-available or Ctrl-C, never checked.
+				case enrich != nil:
+					enrich.add(r)
+				default:
+					finished(r)
+				}
+				if limitAvailable > 0 && r.Status == availability.StatusAvailable && len(found) < limitAvailable {
+					found = append(found, r)
+					if len(found) == limitAvailable {
+						stop()
+					}
+				}
+			})
+			if limitAvailable > 0 {
+				// Arrival order; the canceled rest was never really checked.
+				results = found
+			}
+			if limitAvailable == 0 {
+				results = append(results, skippedResults...)
+			}
+			checked := len(results)
+
+			if enrich != nil {
+				enrich.finish(results)
+			}
+			if err := cfg.registrarCache.Save(); err != nil && cfg.Verbose && !cfg.Quiet {
+				fmt.Fprintf(os.Stderr, "Could not save registrar cache: %v\n", err)
+			}
+			if webhook != nil {
+				sent, failed, err := webhook.close()
+				if failed > 0 {
+					// Shown even with --quiet: automation relies on these.
+					fmt.Fprintf(os.Stderr, "Warning: %d of %d webhook post(s) failed (first: %v)\n", failed, sent+failed, err)
+				} else if sent > 0 && cfg.Verbose && !cfg.Quiet {
+					fmt.Fprintf(os.Stderr, "Posted %d result(s) to the webhook\n", sent)
+				}
+			}
+
+			for i := range results {
+				if score, ok := scores[results[i].Domain]; ok {
+					results[i].Score = score
+				}
+			}
+			attachNearestAvailable(results)
+
+			if path := strings.TrimSpace(cfg.DB); path != "" {
+				if err := appendHistory(cmd.Context(), path, results); err != nil {
+					return &cliError{Code: 1, Err: fmt.Errorf("failed to record history: %w", err), Cmd: cmd}
+				}
+			}
+
+			if quietErrs {
+				// Machine-readable output and --verbose keep the raw errors.
+				strip := !cfg.Verbose && (cfg.outTemplate != nil || (cfg.outFormat != formatJSON && cfg.outFormat != formatNDJSON))
+				if counts := quietErrors(results, strip); len(counts) > 0 && !cfg.Quiet {
+					fmt.Fprintln(os.Stderr, errorSummary(counts))
+				}
+			}
+
+			strictFail := false
+			if cfg.Strict {
+				for _, r := range results {
+					if ignoreInputErrors && isInputError(r) {
+						continue
+					}
+					if r.Status == availability.StatusUnknown || r.Error != "" {
+						strictFail = true
+						break
+					}
+				}
+			}
+
+			if onlyVal == "dropping" && !cfg.Quiet {
+				hasStatus := false
+				for _, r := range results {
+					if len(r.DomainStatus) > 0 {
+						hasStatus = true
+						break
+					}
+				}
+				if !hasStatus {
+					fmt.Fprintln(os.Stderr, "Warning: --only dropping needs registry status codes, but no RDAP/WHOIS response included any")
+				}
 			}
 
 			if onlyVal != "all" {
@@ -84,19 +405,65 @@ dothuntcli --format json --registrar none check example.com
 						if r.Status == availability.StatusTaken {
 							filtered = append(filtered, r)
 						}
+					case "reserved":
+						if r.Status == availability.StatusReserved {
+							filtered = append(filtered, r)
+						}
 					case "unknown":
 						if r.Status == availability.StatusUnknown {
 							filtered = append(filtered, r)
 						}
+					case "dropping":
+						if r.Status == availability.StatusTaken && availability.IsDropping(r.DomainStatus) {
+							filtered = append(filtered, r)
+						}
 					case "buyable":
 						if r.Buyable != nil && *r.Buyable {
 							filtered = append(filtered, r)
 						}
+					case "premium":
+						if r.Premium != nil && *r.Premium {
+							filtered = append(filtered, r)
+						}
+					case "nonpremium":
+						if r.Premium != nil && !*r.Premium {
+							filtered = append(filtered, r)
+						}
 					}
 				}
 				results = filtered
 			}
 
+			groupByVal := strings.ToLower(strings.TrimSpace(groupBy))
+			switch groupByVal {
+			case "", "tld":
+			default:
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --group-by %q (use tld)", groupBy), ShowUsage: true, Cmd: cmd}
+			}
+
+			minConf := strings.ToLower(strings.TrimSpace(minConfidence))
+			if minConf == "" {
+				minConf = "low"
+			}
+			minRank := confidenceRank(minConf)
+			if minRank == 0 {
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --min-confidence %q (use low|medium|high)", minConfidence), ShowUsage: true, Cmd: cmd}
+			}
+			if minRank > confidenceRank("low") {
+				filtered := results[:0]
+				for _, r := range results {
+					if confidenceRank(r.Confidence) >= minRank {
+						filtered = append(filtered, r)
+					}
+				}
+				results = filtered
+			}
+
+			filteredOut := checked > 0 && len(results) == 0
+			if filteredOut && (requireResults || cfg.Verbose) && !cfg.Quiet {
+				fmt.Fprintf(os.Stderr, "All %d checked domain(s) were filtered out\n", checked)
+			}
+
 			sortVal := strings.ToLower(strings.TrimSpace(sortBy))
 			if sortVal == "" {
 				sortVal = "input"
@@ -110,7 +477,8 @@ dothuntcli --format json --registrar none check example.com
 				order := map[availability.Status]int{
 					availability.StatusAvailable: 0,
 					availability.StatusTaken:     1,
-					availability.StatusUnknown:   2,
+					availability.StatusReserved:  2,
+					availability.StatusUnknown:   3,
 				}
 				sort.Slice(results, func(i, j int) bool {
 					oi, ok := order[results[i].Status]
@@ -126,6 +494,8 @@ dothuntcli --format json --registrar none check example.com
 					}
 					return results[i].Domain < results[j].Domain
 				})
+			case "score":
+				sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
 			case "length":
 				sort.Slice(results, func(i, j int) bool {
 					li := len(results[i].Domain)
@@ -135,14 +505,52 @@ dothuntcli --format json --registrar none check example.com
 					}
 					return results[i].Domain < results[j].Domain
 				})
+			case "random":
+				seed := cfg.Seed
+				if seed == 0 {
+					seed = uint64(time.Now().UnixNano())
+				}
+				shuffleResults(results, seed)
 			default:
-				return &cliError{Code: 2, Err: fmt.Errorf("invalid --sort %q (use input|domain|status|length)", sortBy), ShowUsage: true, Cmd: cmd}
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid --sort %q (use input|domain|status|length|score|random)", sortBy), ShowUsage: true, Cmd: cmd}
 			}
 
-			if err := writeResults(os.Stdout, cfg.outFormat, results); err != nil {
+			opts := writerOptions{fields: cfg.outFields, plainColumns: cfg.outColumns, buffered: cfg.ndjsonBuffered}
+			var writer ResultWriter = newResultWriter(cfg.outFormat, opts)
+			// Status files are always files: buffer them regardless.
+			opts.buffered = true
+			fileWriter := newResultWriter(cfg.outFormat, opts)
+			if cfg.outTemplate != nil {
+				writer = templateWriter{tmpl: cfg.outTemplate}
+				fileWriter = writer
+			}
+			writeErr := writer.Write(os.Stdout, results)
+			if writeErr == nil {
+				paths := make(map[availability.Status]string)
+				for status, path := range statusFiles {
+					if p := strings.TrimSpace(*path); p != "" {
+						paths[status] = p
+					}
+				}
+				writeErr = writeStatusFiles(paths, fileWriter, results)
+			}
+			if writeErr == nil && groupByVal == "tld" {
+				// Only the table gets its summary on stdout; anything else
+				// would break the JSON/NDJSON/plain stream scripts parse.
+				summaryOut := io.Writer(os.Stderr)
+				if cfg.outFormat == formatTable && cfg.outTemplate == nil && cfg.outColumns == nil {
+					summaryOut = os.Stdout
+				}
+				writeErr = writeTLDSummary(summaryOut, cfg.outFormat, results)
+			}
+			if err := writeErr; err != nil {
 				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
 			}
-			if strictFail {
+			if checkErr != nil {
+				// The partial results are out; fail so automation notices.
+				return &cliError{Code: 1, Err: fmt.Errorf("aborted early: %w (see --max-unknown-ratio)", checkErr), Cmd: cmd}
+			}
+			if strictFail || (requireResults && filteredOut) {
 				return &cliError{Code: 1}
 			}
 			return nil
@@ -151,8 +559,44 @@ dothuntcli --format json --registrar none check example.com
 
 	cmd.SetFlagErrorFunc(usageErr)
 	cmd.Flags().BoolVar(&availableOnly, "available-only", false, "Only output AVAILABLE results")
-	cmd.Flags().StringVar(&only, "only", "all", "Filter output: all|available|taken|unknown|buyable")
-	cmd.Flags().StringVar(&sortBy, "sort", "input", "Sort output: input|domain|status|length")
+	cmd.Flags().StringVar(&only, "only", "all", "Filter output: all|available|taken|reserved|unknown|dropping|buyable|premium|nonpremium")
+	cmd.Flags().StringVar(&minConfidence, "min-confidence", "low", "Drop results below this confidence: low|medium|high")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Print a per-group summary after results: tld")
+	cmd.Flags().StringVar(&sortBy, "sort", "input", "Sort output: input|domain|status|length|score (highest first)|random (see --seed)")
+	cmd.Flags().BoolVar(&stripWWW, "strip-www", true, "Drop a leading www. before checking (--strip-www=false to keep it)")
+	cmd.Flags().BoolVar(&baseDomain, "base-domain", false, "Reduce subdomains to their registrable domain (www.example.co.uk -> example.co.uk)")
+	cmd.Flags().BoolVar(&keepDuplicates, "keep-duplicates", false, "Check repeated domains again instead of collapsing them")
+	cmd.Flags().BoolVar(&requireResults, "require-results", false, "Exit 1 if filters leave no results")
+	cmd.Flags().BoolVar(&normalizeOnly, "normalize-only", false, "Print normalized, de-duplicated ASCII domains instead of checking them")
+	cmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "Exit 0 with no output when no domains are given (e.g. empty stdin)")
+	for _, status := range []availability.Status{availability.StatusAvailable, availability.StatusTaken, availability.StatusReserved, availability.StatusUnknown} {
+		statusFiles[status] = cmd.Flags().String("output-"+string(status), "", fmt.Sprintf("Also write %s results to this file, in the output format", status))
+	}
+	cmd.Flags().IntVar(&limitAvailable, "limit-available", 0, "Stop after N available domains and print just those, in the order found (0 = check everything)")
+	cmd.Flags().BoolVar(&ignoreInputErrors, "ignore-input-errors", false, "With --strict, don't fail the run for malformed input domains (lookup failures still count)")
+	cmd.Flags().BoolVar(&quietErrs, "quiet-errors", false, "Replace per-domain lookup errors with their category and print one summary to stderr (json/ndjson and --verbose keep the raw errors)")
+	cmd.Flags().BoolVar(&strictTLDs, "strict-tlds", false, "Fail instead of warning when a domain's TLD doesn't exist")
+	cmd.Flags().BoolVar(&allowUnknownTLDs, "allow-unknown-tlds", false, "Check domains whose TLD is in neither the public suffix list nor the RDAP bootstrap instead of skipping them")
+	cmd.Flags().StringVar(&resumePath, "resume", "", "NDJSON state file: record each finished domain and skip ones already recorded (restartable runs)")
+	cmd.Flags().BoolVar(&tldsFromStdin, "tlds-from-stdin", false, "Treat args as labels and stdin as a TLD list (one per line); check every label.tld")
+	cmd.Flags().StringVar(&webhookURL, "webhook", "", "POST each matching result as JSON to this URL as it completes (retries 5xx)")
+	cmd.Flags().StringVar(&webhookOn, "webhook-on", "available", "Results to post to --webhook: available|buyable|all")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "lines", "Stdin format: lines|csv")
+	cmd.Flags().StringVar(&csvColumn, "csv-column", "domain", "CSV column holding domains (header name or 0-based index)")
 
 	return cmd
 }
+
+// confidenceRank orders confidence levels; unknown levels rank 0.
+func confidenceRank(c string) int {
+	switch strings.ToLower(strings.TrimSpace(c)) {
+	case "low":
+		return 1
+	case "medium":
+		return 2
+	case "high":
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doctorTakenDomain = "example.com"
+	doctorTLD         = "com"
+)
+
+type doctorCheck struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Critical   bool   `json:"critical"`
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+func newDoctorCmd(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Run connectivity and cache self-tests",
+		Example: strings.TrimSpace(`
+dothuntcli doctor
+dothuntcli --ndjson doctor
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var checks []doctorCheck
+
+			run := func(name string, critical bool, fn func() (string, error)) {
+				start := time.Now()
+				detail, err := fn()
+				c := doctorCheck{Name: name, OK: err == nil, Critical: critical, DurationMs: time.Since(start).Milliseconds(), Detail: detail}
+				if err != nil {
+					c.Detail = err.Error()
+				}
+				checks = append(checks, c)
+			}
+
+			run("cache dir writable", false, func() (string, error) {
+				dir, err := os.UserCacheDir()
+				if err != nil {
+					return "", err
+				}
+				dir = filepath.Join(dir, "dothuntcli")
+				return dir, checkDirWritable(dir)
+			})
+			run("rdap bootstrap", true, func() (string, error) {
+				if err := cfg.rdapClient.Prefetch(ctx); err != nil {
+					return "", err
+				}
+				urls, err := cfg.rdapClient.ServiceURLs(ctx, doctorTLD)
+				if err != nil {
+					return "", err
+				}
+				if len(urls) == 0 {
+					return "", fmt.Errorf("no rdap service for %s", doctorTLD)
+				}
+				return urls[0], nil
+			})
+			if !cfg.NoWHOIS {
+				run("whois server for "+doctorTLD, true, func() (string, error) {
+					return cfg.whoisClient.ServerForTLD(ctx, doctorTLD)
+				})
+			}
+			run("known-taken lookup", true, func() (string, error) {
+				return expectStatus(ctx, cfg.checker, doctorTakenDomain, availability.StatusTaken)
+			})
+			run("known-available lookup", true, func() (string, error) {
+				name := fmt.Sprintf("dothunt-selftest-%d.%s", time.Now().UnixNano(), doctorTLD)
+				return expectStatus(ctx, cfg.checker, name, availability.StatusAvailable)
+			})
+
+			if err := writeDoctorChecks(os.Stdout, cfg.outFormat, checks); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+			}
+			for _, c := range checks {
+				if c.Critical && !c.OK {
+					return &cliError{Code: 1}
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(usageErr)
+
+	return cmd
+}
+
+// checkDirWritable creates dir if needed and round-trips a temp file in it.
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_, werr := f.WriteString("ok")
+	cerr := f.Close()
+	rerr := os.Remove(name)
+	if werr != nil {
+		return werr
+	}
+	if cerr != nil {
+		return cerr
+	}
+	return rerr
+}
+
+func expectStatus(ctx context.Context, checker *availability.Checker, name string, want availability.Status) (string, error) {
+	r := checker.CheckDomains(ctx, []string{name})[0]
+	detail := fmt.Sprintf("%s: %s via %s", name, r.Status, r.Method)
+	if r.Status != want {
+		reason := r.Detail
+		if r.Error != "" {
+			reason = r.Error
+		}
+		return "", fmt.Errorf("%s, want %s (%s)", detail, want, reason)
+	}
+	return detail, nil
+}
+
+func writeDoctorChecks(w io.Writer, format outputFormat, checks []doctorCheck) error {
+	switch format {
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, c := range checks {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		if checks == nil {
+			checks = []doctorCheck{}
+		}
+		return json.NewEncoder(w).Encode(checks)
+	case formatPlain:
+		for _, c := range checks {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.Name, passFail(c.OK), c.DurationMs, c.Detail); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := domain.NewTabWriter(w)
+		fmt.Fprintln(tw, "CHECK\tRESULT\tTIME\tDETAIL")
+		for _, c := range checks {
+			result := passFail(c.OK)
+			if !c.OK && !c.Critical {
+				result = "warn"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%dms\t%s\n", c.Name, result, c.DurationMs, c.Detail)
+		}
+		return tw.Flush()
+	}
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "fail"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/history"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <domain>",
+		Short: "Show recorded check results for a domain (requires --db)",
+		Example: strings.TrimSpace(`
+dothuntcli --db sweeps.db check example.com
+dothuntcli --db sweeps.db history example.com
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := strings.TrimSpace(cfg.DB)
+			if path == "" {
+				return &cliError{Code: 2, Err: fmt.Errorf("history requires --db <path>"), ShowUsage: true, Cmd: cmd}
+			}
+			name, err := domain.NormalizeWithProfile(args[0], cfg.idnaProfile)
+			if err != nil {
+				return &cliError{Code: 2, Err: fmt.Errorf("invalid domain %q: %w", args[0], err), ShowUsage: true, Cmd: cmd}
+			}
+			if _, err := os.Stat(path); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to open history db: %w", err), Cmd: cmd}
+			}
+
+			store, err := history.Open(cmd.Context(), path)
+			if err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to open history db: %w", err), Cmd: cmd}
+			}
+			defer store.Close()
+
+			entries, err := store.Timeline(cmd.Context(), name)
+			if err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to read history: %w", err), Cmd: cmd}
+			}
+			if err := writeHistoryEntries(os.Stdout, cfg.outFormat, entries); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(usageErr)
+
+	return cmd
+}
+
+func appendHistory(ctx context.Context, path string, results []availability.Result) error {
+	// Invalid inputs were never looked up; their Domain is the raw input.
+	checked := make([]availability.Result, 0, len(results))
+	for _, r := range results {
+		if !isInputError(r) {
+			checked = append(checked, r)
+		}
+	}
+	store, err := history.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := store.Append(ctx, checked); err != nil {
+		store.Close()
+		return err
+	}
+	return store.Close()
+}
+
+func writeHistoryEntries(w io.Writer, format outputFormat, entries []history.Entry) error {
+	switch format {
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		return json.NewEncoder(w).Encode(entries)
+	case formatPlain:
+		for _, e := range entries {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.CheckedAt, e.Domain, e.Status, e.Method, e.Confidence); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := domain.NewTabWriter(w)
+		fmt.Fprintln(tw, "CHECKED_AT\tSTATUS\tMETHOD\tCONFIDENCE\tPRICE")
+		for _, e := range entries {
+			price := e.Price
+			if price == "" {
+				price = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.CheckedAt, e.Status, e.Method, e.Confidence, price)
+		}
+		return tw.Flush()
+	}
+}
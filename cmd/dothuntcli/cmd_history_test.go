@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/history"
+)
+
+func TestAppendHistory_SkipsInputErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+	err := appendHistory(ctx, path, []availability.Result{
+		{Domain: "example.com", Status: availability.StatusTaken, CheckedAt: "2026-01-01T00:00:00Z"},
+		{Domain: "bad..input", Status: availability.StatusUnknown, Error: "invalid domain", Detail: "invalid input"},
+	})
+	if err != nil {
+		t.Fatalf("appendHistory: %v", err)
+	}
+
+	s, err := history.Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	for name, want := range map[string]int{"example.com": 1, "bad..input": 0} {
+		entries, err := s.Timeline(ctx, name)
+		if err != nil || len(entries) != want {
+			t.Fatalf("Timeline(%s)=%v, %v; want %d entries", name, entries, err, want)
+		}
+	}
+}
+
+func TestRun_HistoryUsesIDNAProfile(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	db := filepath.Join(t.TempDir(), "history.db")
+	got := runWithArgsCaptured(t, "--demo", "--registrar", "none", "--idna-profile", "punycode", "--db", db, "--plain", "check", "ⅷ.com")
+	if got.code != 0 {
+		t.Fatalf("check: exit=%d stderr=%q, want 0", got.code, got.stderr)
+	}
+	got = runWithArgsCaptured(t, "--idna-profile", "punycode", "--db", db, "--plain", "history", "ⅷ.com")
+	if got.code != 0 || !strings.Contains(got.stdout, "xn--e5g.com\tavailable") {
+		t.Fatalf("history: exit=%d stdout=%q stderr=%q, want the punycode entry", got.code, got.stdout, got.stderr)
+	}
+}
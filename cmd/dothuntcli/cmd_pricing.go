@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/registrar"
+	"github.com/spf13/cobra"
+)
+
+type pricingEntry struct {
+	TLD       string `json:"tld"`
+	Registrar string `json:"registrar"`
+	registrar.Price
+	Error string `json:"error,omitempty"`
+}
+
+func newPricingCmd(cfg *config) *cobra.Command {
+	var tldsFlag string
+
+	cmd := &cobra.Command{
+		Use:   "pricing",
+		Short: "Show registrar list prices per TLD (registration/renewal/transfer)",
+		Example: strings.TrimSpace(`
+dothuntcli --registrar porkbun pricing --tlds com,io
+dothuntcli --ndjson pricing
+`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lister, ok := cfg.registrar.(registrar.PriceLister)
+			if !ok {
+				return &cliError{Code: 2, Err: fmt.Errorf("pricing requires a registrar that publishes TLD prices (--registrar porkbun)"), ShowUsage: true, Cmd: cmd}
+			}
+
+			var tlds []string
+			for _, in := range splitCommaList(tldsFlag) {
+				tld, err := domain.NormalizeTLD(in)
+				if err != nil {
+					return &cliError{Code: 2, Err: fmt.Errorf("invalid --tlds entry %q: %w", in, err), ShowUsage: true, Cmd: cmd}
+				}
+				tlds = append(tlds, tld)
+			}
+
+			prices, err := lister.ListTLDPricing(cmd.Context())
+			if err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to fetch pricing: %w", err), Cmd: cmd}
+			}
+			if len(tlds) == 0 {
+				for tld := range prices {
+					tlds = append(tlds, tld)
+				}
+				sort.Strings(tlds)
+			}
+
+			name := cfg.registrar.Name()
+			entries := make([]pricingEntry, 0, len(tlds))
+			missing := false
+			for _, tld := range tlds {
+				e := pricingEntry{TLD: tld, Registrar: name}
+				if p, ok := prices[tld]; ok {
+					e.Price = p
+				} else {
+					e.Error = "not offered"
+					missing = true
+				}
+				entries = append(entries, e)
+			}
+
+			if err := writePricingEntries(os.Stdout, cfg.outFormat, entries); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+			}
+			if cfg.Strict && missing {
+				return &cliError{Code: 1}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(usageErr)
+	cmd.Flags().StringVar(&tldsFlag, "tlds", "", "Comma-separated TLDs to show (default: all)")
+
+	return cmd
+}
+
+func writePricingEntries(w io.Writer, format outputFormat, entries []pricingEntry) error {
+	switch format {
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		return json.NewEncoder(w).Encode(entries)
+	case formatPlain:
+		for _, e := range entries {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.TLD, e.Registration, e.Renewal, e.Transfer); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := domain.NewTabWriter(w)
+		fmt.Fprintln(tw, "TLD\tREGISTER\tRENEW\tTRANSFER\tREGISTRAR")
+		for _, e := range entries {
+			if e.Error != "" {
+				fmt.Fprintf(tw, "%s\t-\t-\t-\t%s (%s)\n", e.TLD, e.Registrar, e.Error)
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.TLD, dash(e.Registration), dash(e.Renewal), dash(e.Transfer), e.Registrar)
+		}
+		return tw.Flush()
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
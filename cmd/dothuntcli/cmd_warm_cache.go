@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+type warmCacheEntry struct {
+	TLD         string   `json:"tld"`
+	RDAPURLs    []string `json:"rdap_urls,omitempty"`
+	WHOISServer string   `json:"whois_server,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func newWarmCacheCmd(cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "warm-cache [tld...]",
+		Short: "Prefetch the RDAP bootstrap and WHOIS servers for TLDs (args and/or stdin)",
+		Example: strings.TrimSpace(`
+dothuntcli warm-cache com io de
+printf "com\nio\n" | dothuntcli --ndjson warm-cache
+`),
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inputs, err := readDomainsFromArgsAndStdin(args, os.Stdin, nil)
+			if err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to read tlds: %w", err), Cmd: cmd}
+			}
+
+			ctx := cmd.Context()
+			if err := cfg.rdapClient.Prefetch(ctx); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to fetch RDAP bootstrap: %w", err), Cmd: cmd}
+			}
+
+			var entries []warmCacheEntry
+			failed := false
+			seen := map[string]struct{}{}
+			for _, in := range inputs {
+				tld, err := domain.NormalizeTLD(in)
+				if err != nil {
+					entries = append(entries, warmCacheEntry{TLD: strings.TrimSpace(in), Error: err.Error()})
+					failed = true
+					continue
+				}
+				if _, ok := seen[tld]; ok {
+					continue
+				}
+				seen[tld] = struct{}{}
+
+				e := warmCacheEntry{TLD: tld}
+				e.RDAPURLs, _ = cfg.rdapClient.ServiceURLs(ctx, tld)
+				if !cfg.NoWHOIS {
+					server, err := cfg.whoisClient.ServerForTLD(ctx, tld)
+					if err != nil {
+						if ctx.Err() != nil {
+							return ctx.Err()
+						}
+						e.Error = err.Error()
+					}
+					e.WHOISServer = server
+				}
+				if len(e.RDAPURLs) == 0 && e.WHOISServer == "" {
+					failed = true
+				}
+				entries = append(entries, e)
+			}
+
+			if err := writeWarmCacheEntries(os.Stdout, cfg.outFormat, entries); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+			}
+			if cfg.Strict && failed {
+				return &cliError{Code: 1}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(usageErr)
+
+	return cmd
+}
+
+func writeWarmCacheEntries(w io.Writer, format outputFormat, entries []warmCacheEntry) error {
+	switch format {
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		if entries == nil {
+			entries = []warmCacheEntry{}
+		}
+		return json.NewEncoder(w).Encode(entries)
+	case formatPlain:
+		for _, e := range entries {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", e.TLD, strings.Join(e.RDAPURLs, ","), e.WHOISServer); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := domain.NewTabWriter(w)
+		fmt.Fprintln(tw, "TLD\tRDAP\tWHOIS\tERROR")
+		for _, e := range entries {
+			rdapStr := "-"
+			if len(e.RDAPURLs) > 0 {
+				rdapStr = e.RDAPURLs[0]
+				if len(e.RDAPURLs) > 1 {
+					rdapStr = fmt.Sprintf("%s (+%d)", rdapStr, len(e.RDAPURLs)-1)
+				}
+			}
+			whoisStr := e.WHOISServer
+			if whoisStr == "" {
+				whoisStr = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.TLD, rdapStr, whoisStr, e.Error)
+		}
+		return tw.Flush()
+	}
+}
@@ -13,6 +13,9 @@ const (
 	porkbunSecretAPIKeyEnv        = "PORKBUN_SECRET_API_KEY"
 	porkbunCredentialsFilePathEnv = "DOTHUNTCLI_PORKBUN_CREDENTIALS_FILE"
 
+	nameComUsernameEnv = "NAMECOM_USERNAME"
+	nameComTokenEnv    = "NAMECOM_TOKEN"
+
 	porkbunKeychainService             = "dothuntcli/porkbun"
 	porkbunAPIKeyKeychainAccount       = "api-key"
 	porkbunSecretAPIKeyKeychainAccount = "secret-api-key"
@@ -85,6 +88,22 @@ func loadPorkbunCredentials() (porkbunCredentials, error) {
 	return creds, nil
 }
 
+type nameComCredentials struct {
+	Username string
+	Token    string
+}
+
+func (creds nameComCredentials) complete() bool {
+	return creds.Username != "" && creds.Token != ""
+}
+
+func loadNameComCredentials() nameComCredentials {
+	return nameComCredentials{
+		Username: strings.TrimSpace(os.Getenv(nameComUsernameEnv)),
+		Token:    strings.TrimSpace(os.Getenv(nameComTokenEnv)),
+	}
+}
+
 func porkbunCredentialsFilePath() (path string, explicit bool) {
 	if path := strings.TrimSpace(os.Getenv(porkbunCredentialsFilePathEnv)); path != "" {
 		return path, true
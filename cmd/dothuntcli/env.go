@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const envPrefix = "DOTHUNT_"
+
+// flagEnvName maps a flag name to its environment variable, e.g.
+// "registrar-concurrency" -> "DOTHUNT_REGISTRAR_CONCURRENCY".
+func flagEnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnvDefaults fills flags the user didn't pass explicitly from DOTHUNT_*
+// environment variables. Explicit flags always win.
+func applyEnvDefaults(cmd *cobra.Command) error {
+	var firstErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if firstErr != nil || f.Changed {
+			return
+		}
+		switch f.Name {
+		case "help", "version":
+			return
+		}
+		val, ok := os.LookupEnv(flagEnvName(f.Name))
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(strings.TrimSpace(val)); err != nil {
+			firstErr = fmt.Errorf("invalid %s=%q: %v", flagEnvName(f.Name), val, err)
+		}
+	})
+	return firstErr
+}
+
+// configValue is one flag's effective value and where it came from: "flag",
+// "env" or "default".
+type configValue struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// printConfig writes the effective configuration for cmd as JSON: every flag
+// after env defaults are applied, plus what the "auto" choices resolved to.
+func printConfig(w io.Writer, cmd *cobra.Command, cfg *config) error {
+	flags := make(map[string]configValue)
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		switch f.Name {
+		case "help", "version", "print-config":
+			return
+		}
+		source := "default"
+		if f.Changed {
+			source = "flag"
+		} else if _, ok := os.LookupEnv(flagEnvName(f.Name)); ok {
+			source = "env"
+		}
+		flags[f.Name] = configValue{Value: f.Value.String(), Source: source}
+	})
+
+	format := "template"
+	if cfg.outTemplate == nil {
+		for _, f := range formatNames {
+			if f.format == cfg.outFormat {
+				format = f.name
+			}
+		}
+	}
+	reg := "none"
+	if cfg.registrar != nil {
+		reg = cfg.registrar.Name()
+	}
+	var cacheDir string
+	if d, err := os.UserCacheDir(); err == nil && d != "" {
+		cacheDir = filepath.Join(d, "dothuntcli")
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Version   string                 `json:"version"`
+		Command   string                 `json:"command"`
+		Format    string                 `json:"format"`
+		Registrar string                 `json:"registrar"`
+		CacheDir  string                 `json:"cache_dir,omitempty"`
+		Flags     map[string]configValue `json:"flags"`
+	}{cfg.Version, cmd.CommandPath(), format, reg, cacheDir, flags})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+// errorCategories maps substrings of lookup errors and reasons to a short
+// category, first match wins.
+var errorCategories = []struct {
+	needle   string
+	category string
+}{
+	{"maintenance", "registry maintenance"},
+	{"429", "rate limited"},
+	{"rate limit", "rate limited"},
+	{"too many requests", "rate limited"},
+	{"timeout", "timeout"},
+	{"deadline exceeded", "timeout"},
+	{"unreachable", "connection refused"},
+	{"refused", "connection refused"},
+	{"reset", "connection reset"},
+	{"no such host", "dns failure"},
+	{"no rdap service", "no server"},
+	{"whois server not found", "no server"},
+}
+
+// errorCategory buckets the lookup error of an unknown result, or returns ""
+// when it has none.
+func errorCategory(r availability.Result) string {
+	if r.Status != availability.StatusUnknown || isInputError(r) {
+		return ""
+	}
+	if r.Error == "" && r.RDAPError == "" && r.WHOISError == "" {
+		return ""
+	}
+	s := strings.ToLower(strings.Join([]string{r.Error, r.RDAPError, r.WHOISError, r.RDAPReason, r.WHOISReason}, " "))
+	for _, c := range errorCategories {
+		if strings.Contains(s, c.needle) {
+			return c.category
+		}
+	}
+	return "other"
+}
+
+// quietErrors replaces the verbatim lookup errors of unknown results with
+// their category (--quiet-errors) when strip is set, and returns the count
+// per category either way.
+func quietErrors(results []availability.Result, strip bool) map[string]int {
+	counts := make(map[string]int)
+	for i := range results {
+		r := &results[i]
+		cat := errorCategory(*r)
+		if cat == "" {
+			continue
+		}
+		counts[cat]++
+		if strip {
+			r.Error, r.RDAPError, r.WHOISError = "", "", ""
+			r.Detail = "lookup error: " + cat
+		}
+	}
+	return counts
+}
+
+// errorSummary renders counts as "3 timeout, 1 other", largest first.
+func errorSummary(counts map[string]int) string {
+	cats := make([]string, 0, len(counts))
+	total := 0
+	for cat, n := range counts {
+		cats = append(cats, cat)
+		total += n
+	}
+	sort.Slice(cats, func(i, j int) bool {
+		if counts[cats[i]] != counts[cats[j]] {
+			return counts[cats[i]] > counts[cats[j]]
+		}
+		return cats[i] < cats[j]
+	})
+	parts := make([]string, len(cats))
+	for i, cat := range cats {
+		parts[i] = fmt.Sprintf("%d %s", counts[cat], cat)
+	}
+	return fmt.Sprintf("%d lookup error(s): %s", total, strings.Join(parts, ", "))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestQuietErrors(t *testing.T) {
+	t.Parallel()
+
+	unknown := availability.StatusUnknown
+	results := []availability.Result{
+		{Domain: "a.com", Status: unknown, Detail: "rdap: rdap request failed", Error: "Get \"https://rdap.test/domain/a.com\": context deadline exceeded", RDAPError: "context deadline exceeded"},
+		{Domain: "b.com", Status: unknown, Error: "dial tcp 192.0.2.1:43: i/o timeout"},
+		{Domain: "c.de", Status: unknown, WHOISError: "dial tcp 192.0.2.2:43: connect: connection refused", WHOISReason: "whois unreachable"},
+		{Domain: "d.com", Status: unknown, Error: "something odd"},
+		{Domain: "bad..com", Status: unknown, Detail: "invalid input", Error: "empty label"},
+		{Domain: "e.com", Status: availability.StatusTaken},
+	}
+
+	counts := quietErrors(results, true)
+	if len(counts) != 3 || counts["timeout"] != 2 || counts["connection refused"] != 1 || counts["other"] != 1 {
+		t.Fatalf("counts=%v, want 2 timeout, 1 connection refused, 1 other", counts)
+	}
+	if r := results[0]; r.Error != "" || r.RDAPError != "" || r.Detail != "lookup error: timeout" {
+		t.Fatalf("a.com error=%q rdap_error=%q detail=%q, want the category only", r.Error, r.RDAPError, r.Detail)
+	}
+	if r := results[4]; r.Error != "empty label" {
+		t.Fatalf("bad..com error=%q, want input errors left alone", r.Error)
+	}
+	if got, want := errorSummary(counts), "4 lookup error(s): 2 timeout, 1 connection refused, 1 other"; got != want {
+		t.Fatalf("errorSummary=%q, want %q", got, want)
+	}
+
+	kept := []availability.Result{{Domain: "a.com", Status: unknown, Error: "i/o timeout"}}
+	if counts := quietErrors(kept, false); counts["timeout"] != 1 || kept[0].Error != "i/o timeout" {
+		t.Fatalf("counts=%v error=%q, want counted but kept", counts, kept[0].Error)
+	}
+}
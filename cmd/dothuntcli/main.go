@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -19,9 +20,14 @@ func run() int {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	root := newRootCmd(version)
+	root, cfg := newRootCmd(version)
+	defer cfg.close()
 	executed, err := root.ExecuteContextC(ctx)
 	if err != nil {
+		if cfg.ErrorJSON {
+			return reportErrorJSON(os.Stderr, err)
+		}
+
 		var ce *cliError
 		if errors.As(err, &ce) {
 			if ce.Err != nil && ce.Err.Error() != "" {
@@ -52,6 +58,30 @@ func run() int {
 	return 0
 }
 
+// reportErrorJSON writes err as a single {"error":...,"code":N} line for
+// machine consumers (no usage text) and returns the exit code. Failures that
+// carry no message, like --strict's exit 1, only set the exit code.
+func reportErrorJSON(w io.Writer, err error) int {
+	code, msg := 2, err.Error()
+	var ce *cliError
+	switch {
+	case errors.As(err, &ce):
+		code, msg = ce.Code, ""
+		if ce.Err != nil {
+			msg = ce.Err.Error()
+		}
+	case errors.Is(err, context.Canceled):
+		code = 130
+	}
+	if msg != "" {
+		_ = json.NewEncoder(w).Encode(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{msg, code})
+	}
+	return code
+}
+
 func usageToStderr(cmd interface {
 	SetOut(io.Writer)
 	Usage() error
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -17,6 +22,12 @@ type runResult struct {
 
 func runWithArgsCaptured(t *testing.T, args ...string) runResult {
 	t.Helper()
+	return runWithStdinCaptured(t, "", args...)
+}
+
+// runWithStdinCaptured is runWithArgsCaptured with stdin piped from stdin.
+func runWithStdinCaptured(t *testing.T, stdin string, args ...string) runResult {
+	t.Helper()
 
 	oldArgs := os.Args
 	oldStdin := os.Stdin
@@ -36,6 +47,9 @@ func runWithArgsCaptured(t *testing.T, args ...string) runResult {
 	defer stdoutR.Close()
 	defer stderrR.Close()
 
+	if _, err := io.WriteString(stdinW, stdin); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
 	if err := stdinW.Close(); err != nil {
 		t.Fatalf("close stdin writer: %v", err)
 	}
@@ -97,6 +111,49 @@ func TestRun_InvalidFormatFails(t *testing.T) {
 	}
 }
 
+func TestRun_EnvDefaultsApplyToUnsetFlags(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+	t.Setenv("DOTHUNT_FORMAT", "yaml")
+
+	got := runWithArgsCaptured(t, "check")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	want := `invalid --format "yaml"`
+	if !strings.Contains(got.stderr, want) {
+		t.Fatalf("stderr=%q, want %q", got.stderr, want)
+	}
+}
+
+func TestRun_ExplicitFlagsOverrideEnv(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+	t.Setenv("DOTHUNT_FORMAT", "yaml")
+
+	got := runWithArgsCaptured(t, "--format", "json", "check")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if strings.Contains(got.stderr, "invalid --format") {
+		t.Fatalf("stderr=%q, explicit --format should override DOTHUNT_FORMAT", got.stderr)
+	}
+	if !strings.Contains(got.stderr, "missing domains") {
+		t.Fatalf("stderr=%q, want missing domains error", got.stderr)
+	}
+}
+
+func TestRun_InvalidEnvValueFails(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+	t.Setenv("DOTHUNT_CONCURRENCY", "lots")
+
+	got := runWithArgsCaptured(t, "check")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "DOTHUNT_CONCURRENCY") {
+		t.Fatalf("stderr=%q, want DOTHUNT_CONCURRENCY error", got.stderr)
+	}
+}
+
 func TestRun_HelpGoesToStdout(t *testing.T) {
 	got := runWithArgsCaptured(t, "--help")
 	if got.code != 0 {
@@ -234,6 +291,386 @@ func TestRun_RegistrarPorkbunMissingCredentialsFailsClearly(t *testing.T) {
 	}
 }
 
+func TestRun_InvalidMinConfidenceFails(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--no-whois", "check", "--min-confidence", "certain", "bad..input")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, `invalid --min-confidence "certain"`) {
+		t.Fatalf("stderr=%q, want invalid --min-confidence", got.stderr)
+	}
+}
+
+func TestRun_InvalidTemplateFieldFails(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--template", "{{.Nope}}", "check", "example.com")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "invalid --template") || !strings.Contains(got.stderr, "Nope") {
+		t.Fatalf("stderr=%q, want invalid --template mentioning Nope", got.stderr)
+	}
+}
+
+func TestRun_RequireResultsFailsWhenFilteredEmpty(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	// Invalid input yields an unknown/error result without network access.
+	got := runWithArgsCaptured(t, "--registrar", "none", "--no-whois", "check", "--only", "available", "--require-results", "bad..input")
+	if got.code != 1 {
+		t.Fatalf("exit=%d, want 1", got.code)
+	}
+	if !strings.Contains(got.stderr, "All 1 checked domain(s) were filtered out") {
+		t.Fatalf("stderr=%q, want filtered out message", got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "--no-whois", "check", "--only", "available", "bad..input")
+	if got.code != 0 {
+		t.Fatalf("exit=%d, want 0 without --require-results", got.code)
+	}
+}
+
+func TestRun_HistoryRequiresDB(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "history", "example.com")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "history requires --db") {
+		t.Fatalf("stderr=%q, want --db error", got.stderr)
+	}
+}
+
+func TestRun_CheckAllowEmptyExitsZero(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "check", "--allow-empty")
+	if got.code != 0 {
+		t.Fatalf("exit=%d, want 0", got.code)
+	}
+	if got.stdout != "" || got.stderr != "" {
+		t.Fatalf("stdout=%q stderr=%q, want no output", got.stdout, got.stderr)
+	}
+}
+
+func TestRun_PricingRequiresPriceListingRegistrar(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "pricing")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "pricing requires a registrar") {
+		t.Fatalf("stderr=%q, want registrar error", got.stderr)
+	}
+}
+
+func TestRun_CheckNormalizeOnly(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "check", "--normalize-only", "https://Example.COM/path", "example.com", "bad..input", "bücher.de")
+	if got.code != 0 {
+		t.Fatalf("exit=%d, want 0", got.code)
+	}
+	if want := "example.com\nxn--bcher-kva.de\n"; got.stdout != want {
+		t.Fatalf("stdout=%q, want %q", got.stdout, want)
+	}
+	if !strings.Contains(got.stderr, `invalid domain "bad..input"`) {
+		t.Fatalf("stderr=%q, want invalid domain error", got.stderr)
+	}
+}
+
+func TestRun_CheckIgnoreInputErrors(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	args := []string{"--registrar", "none", "--strict", "check", "--normalize-only", "example.com", "bad..input"}
+	if got := runWithArgsCaptured(t, args...); got.code != 1 {
+		t.Fatalf("exit=%d, want 1 for an invalid input under --strict", got.code)
+	}
+	if got := runWithArgsCaptured(t, append(args, "--ignore-input-errors")...); got.code != 0 {
+		t.Fatalf("exit=%d (stderr %q), want 0 with --ignore-input-errors", got.code, got.stderr)
+	}
+}
+
+func TestRun_OnlyPremiumRequiresRegistrar(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "check", "--only", "nonpremium", "bad..input")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "--only nonpremium requires --registrar") {
+		t.Fatalf("stderr=%q, want registrar requirement", got.stderr)
+	}
+}
+
+func TestRun_RequireRegistrarFailsWithoutCredentials(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--require-registrar", "check", "example.com")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	if !strings.Contains(got.stderr, "--require-registrar: no registrar configured") || !strings.Contains(got.stderr, "PORKBUN_API_KEY") {
+		t.Fatalf("stderr=%q, want registrar error naming env vars", got.stderr)
+	}
+}
+
+func TestRun_CheckStripsWWWByDefault(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "check", "--normalize-only", "www.example.com")
+	if got.stdout != "example.com\n" {
+		t.Fatalf("stdout=%q, want example.com", got.stdout)
+	}
+	if !strings.Contains(got.stderr, "Stripped www. from www.example.com") {
+		t.Fatalf("stderr=%q, want strip warning", got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "check", "--normalize-only", "--strip-www=false", "www.example.com")
+	if got.stdout != "www.example.com\n" {
+		t.Fatalf("stdout=%q, want www.example.com kept", got.stdout)
+	}
+}
+
+func TestRun_ErrorJSON(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--error-json", "--format", "yaml", "check")
+	if got.code != 2 {
+		t.Fatalf("exit=%d, want 2", got.code)
+	}
+	want := `{"error":"invalid --format \"yaml\" (use auto|table|ndjson|json|plain)","code":2}` + "\n"
+	if got.stderr != want {
+		t.Fatalf("stderr=%q, want %q", got.stderr, want)
+	}
+}
+
+func TestRun_PlainColumnsRejectsOtherFormats(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--json", "--plain-columns", "domain", "check", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, "--plain-columns applies to plain output only") {
+		t.Fatalf("exit=%d stderr=%q, want usage error", got.code, got.stderr)
+	}
+}
+
+func TestRun_PrintConfig(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+	t.Setenv("DOTHUNT_CONCURRENCY", "5")
+
+	got := runWithArgsCaptured(t, "--print-config", "--timeout", "3s", "--format", "plain", "check")
+	if got.code != 0 {
+		t.Fatalf("exit=%d stderr=%q, want 0", got.code, got.stderr)
+	}
+	var out struct {
+		Command   string `json:"command"`
+		Format    string `json:"format"`
+		Registrar string `json:"registrar"`
+		Flags     map[string]struct {
+			Value  string `json:"value"`
+			Source string `json:"source"`
+		} `json:"flags"`
+	}
+	if err := json.Unmarshal([]byte(got.stdout), &out); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, got.stdout)
+	}
+	if out.Command != "dothuntcli check" || out.Format != "plain" || out.Registrar != "none" {
+		t.Fatalf("command=%q format=%q registrar=%q, want check/plain/none", out.Command, out.Format, out.Registrar)
+	}
+	for name, want := range map[string]string{"timeout": "3s flag", "concurrency": "5 env", "only": "all default"} {
+		f := out.Flags[name]
+		if got := f.Value + " " + f.Source; got != want {
+			t.Fatalf("flags[%s]=%q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRun_CheckIDNAProfile(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	for profile, want := range map[string]string{
+		"lookup":       "viii.com\n",
+		"punycode":     "xn--e5g.com\n",
+		"registration": "",
+	} {
+		got := runWithArgsCaptured(t, "--registrar", "none", "--idna-profile", profile, "check", "--normalize-only", "ⅷ.com")
+		if got.stdout != want {
+			t.Fatalf("%s: stdout=%q, want %q", profile, got.stdout, want)
+		}
+	}
+
+	// www. stripping and dedupe run before the checker and must not fall
+	// back to the lookup profile.
+	got := runWithArgsCaptured(t, "--registrar", "none", "--idna-profile", "registration", "check", "--normalize-only", "www.ⅷ.com", "viii.com")
+	if got.stdout != "viii.com\n" {
+		t.Fatalf("registration www.: stdout=%q, want only viii.com", got.stdout)
+	}
+
+	got = runWithArgsCaptured(t, "--idna-profile", "loose", "check", "--normalize-only", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, "invalid --idna-profile") {
+		t.Fatalf("exit=%d stderr=%q, want usage error", got.code, got.stderr)
+	}
+}
+
+func TestRun_CheckLimitAvailable(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		if strings.HasSuffix(r.URL.Path, "/a.com") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	args := []string{"--registrar", "none", "--no-whois", "--plain", "--concurrency", "1", "--rdap-url", "com=" + srv.URL, "check", "--limit-available", "2", "a.com", "b.com", "c.com"}
+	for i := range 20 {
+		args = append(args, fmt.Sprintf("x%d.com", i))
+	}
+	got := runWithArgsCaptured(t, args...)
+	if got.code != 0 {
+		t.Fatalf("exit=%d stderr=%q, want 0", got.code, got.stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(got.stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "b.com\t") || !strings.HasPrefix(lines[1], "c.com\t") {
+		t.Fatalf("stdout=%q, want b.com and c.com only", got.stdout)
+	}
+	if n := lookups.Load(); n > 5 {
+		t.Fatalf("rdap lookups=%d, want the run to stop early", n)
+	}
+}
+
+func TestRun_ConcurrencyBounds(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	for _, flag := range []string{"--concurrency", "--registrar-concurrency"} {
+		got := runWithArgsCaptured(t, "--registrar", "none", flag, "-1", "check", "--normalize-only", "example.com")
+		if got.code != 2 || !strings.Contains(got.stderr, "invalid "+flag) {
+			t.Fatalf("%s -1: exit=%d stderr=%q, want usage error", flag, got.code, got.stderr)
+		}
+	}
+
+	got := runWithArgsCaptured(t, "--registrar", "none", "--concurrency", "100000", "--max-concurrency", "8", "check", "--normalize-only", "example.com")
+	if got.code != 0 || !strings.Contains(got.stderr, "Lowering --concurrency 100000 to 8") {
+		t.Fatalf("exit=%d stderr=%q, want clamp warning", got.code, got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "--registrar-concurrency", "500", "check", "--normalize-only", "example.com")
+	if !strings.Contains(got.stderr, "Lowering --registrar-concurrency 500 to 32") {
+		t.Fatalf("stderr=%q, want registrar clamp warning", got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "--registrar-concurrency", "64", "--max-registrar-concurrency", "64", "check", "--normalize-only", "example.com")
+	if got.code != 0 || strings.Contains(got.stderr, "Lowering") {
+		t.Fatalf("exit=%d stderr=%q, want a raised ceiling to allow 64", got.code, got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "--max-registrar-concurrency", "0", "check", "--normalize-only", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, "invalid --max-registrar-concurrency") {
+		t.Fatalf("exit=%d stderr=%q, want usage error", got.code, got.stderr)
+	}
+}
+
+func TestRun_DemoCheck(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--demo", "--plain", "--plain-columns", "domain,status,method,price", "check", "example.com", "dothunt-demo.com", "freename.de")
+	if got.code != 0 {
+		t.Fatalf("exit=%d stderr=%q, want 0", got.code, got.stderr)
+	}
+	want := "example.com\ttaken\trdap\t\n" +
+		"dothunt-demo.com\tavailable\trdap\t10.99\n" +
+		"freename.de\tavailable\twhois\t6.99\n"
+	if got.stdout != want {
+		t.Fatalf("stdout=%q, want %q", got.stdout, want)
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "dothuntcli")); !os.IsNotExist(err) {
+		t.Fatalf("cache dir stat err=%v, want --demo to leave the cache alone", err)
+	}
+
+	got = runWithArgsCaptured(t, "--demo", "--registrar", "porkbun", "check", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, "--demo uses its own stub registrar") {
+		t.Fatalf("exit=%d stderr=%q, want usage error", got.code, got.stderr)
+	}
+}
+
+func TestRun_CheckGroupByKeepsStdoutParseable(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--demo", "--registrar", "none", "--json", "check", "--group-by", "tld", "example.com", "freename.de")
+	var results []map[string]any
+	if err := json.Unmarshal([]byte(got.stdout), &results); got.code != 0 || err != nil || len(results) != 2 {
+		t.Fatalf("exit=%d err=%v stdout=%q, want a lone JSON array", got.code, err, got.stdout)
+	}
+	if !strings.Contains(got.stderr, `"tld_summary"`) {
+		t.Fatalf("stderr=%q, want the summary on stderr", got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--demo", "--registrar", "none", "--plain", "--plain-columns", "domain,status", "check", "--group-by", "tld", "example.com")
+	if got.code != 0 || got.stdout != "example.com\ttaken\n" || !strings.Contains(got.stderr, "com\t0\t1\t0") {
+		t.Fatalf("exit=%d stdout=%q stderr=%q, want summary rows only on stderr", got.code, got.stdout, got.stderr)
+	}
+}
+
+func TestRun_CheckOnlyDroppingWithoutStatuses(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--demo", "--registrar", "none", "--plain", "check", "--only", "dropping", "freename.com")
+	if got.code != 0 || got.stdout != "" || !strings.Contains(got.stderr, "needs registry status codes") {
+		t.Fatalf("exit=%d stdout=%q stderr=%q, want empty output and a warning", got.code, got.stdout, got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--registrar", "none", "check", "--only", "bogus", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, `invalid --only "bogus"`) {
+		t.Fatalf("exit=%d stderr=%q, want usage error before any lookup", got.code, got.stderr)
+	}
+}
+
+func TestRun_CheckSkipsUnknownTLDs(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithArgsCaptured(t, "--demo", "--registrar", "none", "--plain", "--plain-columns", "domain,status", "check", "example.com", "example.zzzz", "other.zzzz")
+	if want := "example.com\ttaken\nexample.zzzz\tunknown\nother.zzzz\tunknown\n"; got.code != 0 || got.stdout != want {
+		t.Fatalf("exit=%d stdout=%q, want example.com checked and unknown rows for the rest", got.code, got.stdout)
+	}
+	if !strings.Contains(got.stderr, `unknown TLD "zzzz"; skipping 2 domain(s)`) {
+		t.Fatalf("stderr=%q, want skip warning", got.stderr)
+	}
+
+	got = runWithArgsCaptured(t, "--demo", "--registrar", "none", "--plain", "--plain-columns", "domain,status", "check", "--allow-unknown-tlds", "example.zzzz")
+	if got.code != 0 || got.stdout != "example.zzzz\tunknown\n" || strings.Contains(got.stderr, "skipping") {
+		t.Fatalf("exit=%d stdout=%q stderr=%q, want example.zzzz checked", got.code, got.stdout, got.stderr)
+	}
+}
+
+func TestRun_CheckTLDsFromStdin(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	got := runWithStdinCaptured(t, "com\n.DE  # registry\ncom\n", "--demo", "--registrar", "none", "--plain", "--plain-columns", "domain,status", "check", "--tlds-from-stdin", "example", "dothunt-demo")
+	want := "example.com\ttaken\nexample.de\ttaken\ndothunt-demo.com\tavailable\ndothunt-demo.de\tavailable\n"
+	if got.code != 0 || got.stdout != want {
+		t.Fatalf("exit=%d stdout=%q stderr=%q, want %q", got.code, got.stdout, got.stderr, want)
+	}
+
+	got = runWithStdinCaptured(t, "com\n", "--registrar", "none", "check", "--tlds-from-stdin", "example.com")
+	if got.code != 2 || !strings.Contains(got.stderr, "takes labels as args") {
+		t.Fatalf("exit=%d stderr=%q, want usage error for a domain arg", got.code, got.stderr)
+	}
+
+	got = runWithStdinCaptured(t, "com\nco.uk\n", "--registrar", "none", "check", "--tlds-from-stdin", "example")
+	if got.code != 1 || !strings.Contains(got.stderr, `invalid TLD "co.uk"`) {
+		t.Fatalf("exit=%d stderr=%q, want invalid TLD error", got.code, got.stderr)
+	}
+}
+
 func isolatePorkbunCredentialSources(t *testing.T) {
 	t.Helper()
 
@@ -242,8 +679,11 @@ func isolatePorkbunCredentialSources(t *testing.T) {
 	t.Setenv(porkbunAPIKeyEnv, "")
 	t.Setenv(porkbunSecretAPIKeyEnv, "")
 	t.Setenv(porkbunCredentialsFilePathEnv, "")
+	t.Setenv(nameComUsernameEnv, "")
+	t.Setenv(nameComTokenEnv, "")
 	t.Setenv("HOME", home)
 	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
 }
 
 func pipe(t *testing.T) (*os.File, *os.File) {
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+// readMethodPolicyFile parses a per-TLD lookup order file:
+//
+//	# tld = methods, tried in order
+//	de = whois,rdap
+//	com = rdap
+//	* = rdap,whois
+func readMethodPolicyFile(path string) (map[string][]availability.Method, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	policy := map[string][]availability.Method{}
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid method policy line %d in %s: want tld=method,...", lineNo, path)
+		}
+
+		tld := strings.TrimSpace(key)
+		if tld != "*" {
+			tld, err = domain.NormalizeTLD(tld)
+			if err != nil {
+				return nil, fmt.Errorf("invalid method policy line %d in %s: %w", lineNo, path, err)
+			}
+		}
+
+		var methods []availability.Method
+		for _, m := range splitCommaList(val) {
+			switch availability.Method(m) {
+			case availability.MethodRDAP, availability.MethodWHOIS:
+				methods = append(methods, availability.Method(m))
+			default:
+				return nil, fmt.Errorf("invalid method policy line %d in %s: unknown method %q (use rdap|whois)", lineNo, path, m)
+			}
+		}
+		if len(methods) == 0 {
+			return nil, fmt.Errorf("invalid method policy line %d in %s: no methods for %q", lineNo, path, tld)
+		}
+		policy[tld] = methods
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read method policy %s: %w", path, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestReadMethodPolicyFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy")
+	writeCredentialsFile(t, path, `
+# registry quirks
+.DE = whois, rdap
+com = rdap
+* = rdap,whois
+`)
+
+	got, err := readMethodPolicyFile(path)
+	if err != nil {
+		t.Fatalf("readMethodPolicyFile: %v", err)
+	}
+	if de := got["de"]; len(de) != 2 || de[0] != availability.MethodWHOIS || de[1] != availability.MethodRDAP {
+		t.Fatalf("de=%v, want [whois rdap]", de)
+	}
+	if com := got["com"]; len(com) != 1 || com[0] != availability.MethodRDAP {
+		t.Fatalf("com=%v, want [rdap]", com)
+	}
+	if len(got["*"]) != 2 {
+		t.Fatalf("*=%v, want default entry", got["*"])
+	}
+}
+
+func TestReadMethodPolicyFile_UnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy")
+	writeCredentialsFile(t, path, "com = dns\n")
+
+	_, err := readMethodPolicyFile(path)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("err=%v, want line-numbered error", err)
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/benithors/dothuntcli/internal/availability"
 	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/registrar"
 	"golang.org/x/term"
 )
 
@@ -21,20 +29,104 @@ const (
 	formatPlain
 )
 
+// ResultWriter renders checked results in one output format.
+type ResultWriter interface {
+	Write(w io.Writer, results []availability.Result) error
+}
+
+// resultWriterFunc adapts a plain function to ResultWriter.
+type resultWriterFunc func(w io.Writer, results []availability.Result) error
+
+func (f resultWriterFunc) Write(w io.Writer, results []availability.Result) error {
+	return f(w, results)
+}
+
+// writerOptions carries the flags that shape a writer.
+type writerOptions struct {
+	fields       []string // JSON keys to keep (--fields); nil keeps all
+	plainColumns []string // plain columns (--plain-columns); nil uses the default
+	buffered     bool     // NDJSON: buffer records instead of flushing each line
+}
+
+// formatNames maps --format names to formats, in the order help lists them.
+var formatNames = []struct {
+	name   string
+	format outputFormat
+}{
+	{"table", formatTable},
+	{"ndjson", formatNDJSON},
+	{"json", formatJSON},
+	{"plain", formatPlain},
+}
+
+// resultWriters builds the writer for each output format. A new format needs
+// an outputFormat constant, a formatNames entry and a constructor here.
+var resultWriters = map[outputFormat]func(opts writerOptions) ResultWriter{
+	formatTable: func(writerOptions) ResultWriter { return resultWriterFunc(writeTable) },
+	formatNDJSON: func(opts writerOptions) ResultWriter {
+		return resultWriterFunc(func(w io.Writer, results []availability.Result) error {
+			return writeNDJSON(w, results, opts.fields, opts.buffered)
+		})
+	},
+	formatJSON: func(opts writerOptions) ResultWriter {
+		return resultWriterFunc(func(w io.Writer, results []availability.Result) error {
+			return writeJSON(w, results, opts.fields)
+		})
+	},
+	formatPlain: func(opts writerOptions) ResultWriter {
+		return resultWriterFunc(func(w io.Writer, results []availability.Result) error {
+			return writePlain(w, results, opts.plainColumns)
+		})
+	},
+}
+
+// newResultWriter returns the registered writer for format, falling back to
+// the table.
+func newResultWriter(format outputFormat, opts writerOptions) ResultWriter {
+	if build, ok := resultWriters[format]; ok {
+		return build(opts)
+	}
+	return resultWriterFunc(writeTable)
+}
+
+// writeStatusFiles routes results to one file per status (--output-available
+// and friends), each rendered by w. Every listed file is created, even when
+// no result has its status, so downstream steps can rely on it existing.
+func writeStatusFiles(paths map[availability.Status]string, w ResultWriter, results []availability.Result) error {
+	byStatus := make(map[availability.Status][]availability.Result, len(paths))
+	for _, r := range results {
+		if _, ok := paths[r.Status]; ok {
+			byStatus[r.Status] = append(byStatus[r.Status], r)
+		}
+	}
+	for status, path := range paths {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = w.Write(f, byStatus[status])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
 func resolveFormat(flagVal string, stdout *os.File) (outputFormat, error) {
 	raw := strings.TrimSpace(flagVal)
-	switch strings.ToLower(raw) {
-	case "table":
-		return formatTable, nil
-	case "ndjson":
-		return formatNDJSON, nil
-	case "json":
-		return formatJSON, nil
-	case "plain":
-		return formatPlain, nil
-	case "auto", "":
-	default:
-		return 0, fmt.Errorf("invalid --format %q (use auto|table|ndjson|json|plain)", raw)
+	name := strings.ToLower(raw)
+	if name != "auto" && name != "" {
+		names := []string{"auto"}
+		for _, f := range formatNames {
+			if f.name == name {
+				return f.format, nil
+			}
+			names = append(names, f.name)
+		}
+		return 0, fmt.Errorf("invalid --format %q (use %s)", raw, strings.Join(names, "|"))
 	}
 
 	if term.IsTerminal(int(stdout.Fd())) {
@@ -43,106 +135,443 @@ func resolveFormat(flagVal string, stdout *os.File) (outputFormat, error) {
 	return formatNDJSON, nil
 }
 
-func writeResults(w io.Writer, format outputFormat, results []availability.Result) error {
-	switch format {
-	case formatNDJSON:
-		enc := json.NewEncoder(w)
-		for _, r := range results {
-			if err := enc.Encode(r); err != nil {
+// resolveNDJSONBuffering reads --json-lines-buffered. "auto" buffers when
+// stdout is a regular file and flushes every line otherwise, so pipes and
+// agents reading the output see each result as soon as it is written.
+func resolveNDJSONBuffering(flagVal string, stdout *os.File) (bool, error) {
+	switch v := strings.ToLower(strings.TrimSpace(flagVal)); v {
+	case "", "auto":
+		st, err := stdout.Stat()
+		return err == nil && st.Mode().IsRegular(), nil
+	default:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid --json-lines-buffered %q (use auto|true|false)", flagVal)
+		}
+		return b, nil
+	}
+}
+
+// ndjsonBufferSize is the write buffer for buffered NDJSON output.
+const ndjsonBufferSize = 64 << 10
+
+// writeNDJSON writes one JSON object per result, limited to fields when set
+// (see parseFields). Unless buffered, every line is handed to w in its own
+// write and flushed if w buffers.
+func writeNDJSON(w io.Writer, results []availability.Result, fields []string, buffered bool) error {
+	var bw *bufio.Writer
+	if buffered {
+		bw = bufio.NewWriterSize(w, ndjsonBufferSize)
+		w = bw
+	}
+	flusher, _ := w.(interface{ Flush() error })
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(projectResult(r, fields)); err != nil {
+			return err
+		}
+		if !buffered && flusher != nil {
+			if err := flusher.Flush(); err != nil {
 				return err
 			}
 		}
-		return nil
-	case formatJSON:
-		enc := json.NewEncoder(w)
+	}
+	if bw != nil {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// writeJSON writes results as a single JSON array.
+func writeJSON(w io.Writer, results []availability.Result, fields []string) error {
+	enc := json.NewEncoder(w)
+	if len(fields) == 0 {
 		return enc.Encode(results)
-	case formatPlain:
-		for _, r := range results {
-			// Stable, line-oriented output for piping.
-			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Domain, r.Status, r.Method, r.Confidence); err != nil {
-				return err
-			}
+	}
+	projected := make([]any, len(results))
+	for i, r := range results {
+		projected[i] = projectResult(r, fields)
+	}
+	return enc.Encode(projected)
+}
+
+// plainColumnNames lists the --plain-columns choices in help order.
+var plainColumnNames = []string{"domain", "unicode", "status", "method", "confidence", "price", "score"}
+
+// defaultPlainColumns is the historical plain layout; keep it stable.
+var defaultPlainColumns = []string{"domain", "status", "method", "confidence"}
+
+var plainColumnValues = map[string]func(r availability.Result) string{
+	"domain":     func(r availability.Result) string { return r.Domain },
+	"unicode":    func(r availability.Result) string { return domain.ToUnicode(r.Domain) },
+	"status":     func(r availability.Result) string { return string(r.Status) },
+	"method":     func(r availability.Result) string { return string(r.Method) },
+	"confidence": func(r availability.Result) string { return r.Confidence },
+	"price":      func(r availability.Result) string { return r.Price },
+	"score":      func(r availability.Result) string { return strconv.Itoa(r.Score) },
+}
+
+// parsePlainColumns validates a --plain-columns list like "domain,unicode".
+func parsePlainColumns(raw string) ([]string, error) {
+	var cols []string
+	for _, c := range strings.Split(raw, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			continue
 		}
-		return nil
-	case formatTable:
-		fallthrough
+		if _, ok := plainColumnValues[c]; !ok {
+			return nil, fmt.Errorf("unknown --plain-columns name %q (use %s)", c, strings.Join(plainColumnNames, ","))
+		}
+		cols = append(cols, c)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("--plain-columns needs at least one column")
+	}
+	return cols, nil
+}
+
+func writePlain(w io.Writer, results []availability.Result, columns []string) error {
+	if len(columns) == 0 {
+		columns = defaultPlainColumns
+	}
+	vals := make([]string, len(columns))
+	for _, r := range results {
+		// Stable, line-oriented output for piping.
+		for i, c := range columns {
+			vals[i] = plainColumnValues[c](r)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(vals, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, results []availability.Result) error {
+	showScore := false
+	for _, r := range results {
+		if r.Score != 0 {
+			showScore = true
+			break
+		}
+	}
+	showRegistrar := false
+	for _, r := range results {
+		if r.Buyable != nil || r.Premium != nil || r.Price != "" || r.Registrar != "" {
+			showRegistrar = true
+			break
+		}
+	}
+
+	tw := domain.NewTabWriter(w)
+	switch {
+	case showScore && showRegistrar:
+		fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tSCORE\tBUYABLE\tPREMIUM\tPRICE\tREGISTRAR\tDETAIL")
+	case showScore:
+		fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tSCORE\tDETAIL")
+	case showRegistrar:
+		fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tBUYABLE\tPREMIUM\tPRICE\tREGISTRAR\tDETAIL")
 	default:
-		showScore := false
-		for _, r := range results {
-			if r.Score != 0 {
-				showScore = true
-				break
+		fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tDETAIL")
+	}
+	for _, r := range results {
+		detail := r.Detail
+		if detail == "" && r.Error != "" {
+			detail = r.Error
+		}
+		if r.Privacy != nil && *r.Privacy {
+			if detail != "" {
+				detail += "; "
 			}
+			detail += "owner redacted"
 		}
-		showRegistrar := false
-		for _, r := range results {
-			if r.Buyable != nil || r.Premium != nil || r.Price != "" || r.Registrar != "" {
-				showRegistrar = true
-				break
+		for _, w := range r.Warnings {
+			if detail != "" {
+				detail += "; "
 			}
+			detail += "warning: " + w
+		}
+
+		var buyableStr, premiumStr, priceStr, registrarStr string
+		if r.Buyable != nil {
+			if *r.Buyable {
+				buyableStr = "yes"
+			} else {
+				buyableStr = "no"
+			}
+		}
+		if r.Premium != nil {
+			if *r.Premium {
+				premiumStr = "yes"
+			} else {
+				premiumStr = "no"
+			}
+		}
+		if r.Price != "" {
+			priceStr = r.Price
+			var notes []string
+			if r.RegularPrice != "" && r.RegularPrice != r.Price {
+				notes = append(notes, "reg "+r.RegularPrice)
+			}
+			if r.RenewalPrice != "" && r.RenewalPrice != r.Price && r.RenewalPrice != r.RegularPrice {
+				notes = append(notes, "renews "+r.RenewalPrice)
+			}
+			if len(notes) > 0 {
+				priceStr = fmt.Sprintf("%s (%s)", r.Price, strings.Join(notes, ", "))
+			}
+			if r.Currency != "" {
+				priceStr = priceStr + " " + r.Currency
+			}
+		}
+		if r.Registrar != "" {
+			registrarStr = r.Registrar
+		}
+		if r.RegistrarError != "" && registrarStr != "" {
+			registrarStr = registrarStr + " (err)"
 		}
 
-		tw := domain.NewTabWriter(w)
 		switch {
 		case showScore && showRegistrar:
-			fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tSCORE\tBUYABLE\tPREMIUM\tPRICE\tREGISTRAR\tDETAIL")
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+				r.Domain, r.Status, r.Method, r.Confidence, r.Score, buyableStr, premiumStr, priceStr, registrarStr, detail)
 		case showScore:
-			fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tSCORE\tDETAIL")
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", r.Domain, r.Status, r.Method, r.Confidence, r.Score, detail)
 		case showRegistrar:
-			fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tBUYABLE\tPREMIUM\tPRICE\tREGISTRAR\tDETAIL")
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Domain, r.Status, r.Method, r.Confidence, buyableStr, premiumStr, priceStr, registrarStr, detail)
 		default:
-			fmt.Fprintln(tw, "DOMAIN\tSTATUS\tMETHOD\tCONFIDENCE\tDETAIL")
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Domain, r.Status, r.Method, r.Confidence, detail)
 		}
-		for _, r := range results {
-			detail := r.Detail
-			if detail == "" && r.Error != "" {
-				detail = r.Error
-			}
+	}
+	return tw.Flush()
+}
 
-			var buyableStr, premiumStr, priceStr, registrarStr string
-			if r.Buyable != nil {
-				if *r.Buyable {
-					buyableStr = "yes"
-				} else {
-					buyableStr = "no"
-				}
-			}
-			if r.Premium != nil {
-				if *r.Premium {
-					premiumStr = "yes"
-				} else {
-					premiumStr = "no"
-				}
-			}
-			if r.Price != "" {
-				priceStr = r.Price
-				if r.RegularPrice != "" && r.RegularPrice != r.Price {
-					priceStr = fmt.Sprintf("%s (reg %s)", r.Price, r.RegularPrice)
-				}
-				if r.Currency != "" {
-					priceStr = priceStr + " " + r.Currency
-				}
-			}
-			if r.Registrar != "" {
-				registrarStr = r.Registrar
-			}
-			if r.RegistrarError != "" && registrarStr != "" {
-				registrarStr = registrarStr + " (err)"
-			}
+// tldCounts tallies results for one TLD in a --group-by tld summary.
+type tldCounts struct {
+	Available int `json:"available"`
+	Taken     int `json:"taken"`
+	Unknown   int `json:"unknown"`
+	Buyable   int `json:"buyable"`
+	Reserved  int `json:"reserved"`
+}
+
+// summarizeByTLD groups results by TLD; inputs without one count under "-".
+func summarizeByTLD(results []availability.Result) (tlds []string, counts map[string]*tldCounts) {
+	counts = make(map[string]*tldCounts)
+	for _, r := range results {
+		tld := r.TLD
+		if tld == "" {
+			tld = "-"
+		}
+		c, ok := counts[tld]
+		if !ok {
+			c = &tldCounts{}
+			counts[tld] = c
+			tlds = append(tlds, tld)
+		}
+		switch r.Status {
+		case availability.StatusAvailable:
+			c.Available++
+		case availability.StatusTaken:
+			c.Taken++
+		case availability.StatusReserved:
+			c.Reserved++
+		default:
+			c.Unknown++
+		}
+		if r.Buyable != nil && *r.Buyable {
+			c.Buyable++
+		}
+	}
+	sort.Strings(tlds)
+	return tlds, counts
+}
 
-			switch {
-			case showScore && showRegistrar:
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
-					r.Domain, r.Status, r.Method, r.Confidence, r.Score, buyableStr, premiumStr, priceStr, registrarStr, detail)
-			case showScore:
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", r.Domain, r.Status, r.Method, r.Confidence, r.Score, detail)
-			case showRegistrar:
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-					r.Domain, r.Status, r.Method, r.Confidence, buyableStr, premiumStr, priceStr, registrarStr, detail)
-			default:
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Domain, r.Status, r.Method, r.Confidence, detail)
+// writeTLDSummary prints the per-TLD summary: a {"tld_summary": {...}}
+// object for JSON formats, tab-separated rows for plain output and a table
+// section otherwise. Callers send it to stderr unless stdout is a table.
+func writeTLDSummary(w io.Writer, format outputFormat, results []availability.Result) error {
+	tlds, counts := summarizeByTLD(results)
+	switch format {
+	case formatNDJSON, formatJSON:
+		return json.NewEncoder(w).Encode(map[string]map[string]*tldCounts{"tld_summary": counts})
+	case formatPlain:
+		for _, tld := range tlds {
+			c := counts[tld]
+			if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", tld, c.Available, c.Taken, c.Unknown, c.Buyable, c.Reserved); err != nil {
+				return err
 			}
 		}
+		return nil
+	default:
+		fmt.Fprintln(w)
+		tw := domain.NewTabWriter(w)
+		fmt.Fprintln(tw, "TLD\tAVAILABLE\tTAKEN\tUNKNOWN\tBUYABLE\tRESERVED")
+		for _, tld := range tlds {
+			c := counts[tld]
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", tld, c.Available, c.Taken, c.Unknown, c.Buyable, c.Reserved)
+		}
 		return tw.Flush()
 	}
 }
+
+var templateFuncs = template.FuncMap{
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	"join":  strings.Join,
+	// default returns def when v is empty (zero value or nil pointer), e.g.
+	// {{default "-" .Price}}.
+	"default": func(def, v any) any {
+		if v == nil {
+			return def
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return def
+			}
+			return rv.Elem().Interface()
+		}
+		if rv.IsZero() {
+			return def
+		}
+		return v
+	},
+	// yesno renders optional booleans like Buyable/Premium as yes/no/"".
+	"yesno": func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		if *b {
+			return "yes"
+		}
+		return "no"
+	},
+}
+
+// resultFieldNames lists the JSON keys of availability.Result in struct order.
+func resultFieldNames() []string {
+	t := reflect.TypeFor[availability.Result]()
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseFields validates a --fields list like "domain,status,price" against
+// the result's JSON keys. Duplicates are dropped.
+func parseFields(raw string) ([]string, error) {
+	known := resultFieldNames()
+	var fields []string
+	seen := map[string]bool{}
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		if !slices.Contains(known, f) {
+			return nil, fmt.Errorf("unknown --fields name %q (known: %s)", f, strings.Join(known, ","))
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("--fields needs at least one field name")
+	}
+	return fields, nil
+}
+
+// projectedResult encodes only the selected keys of a result, in the order
+// given. Keys the result omits (omitempty) stay omitted.
+type projectedResult struct {
+	r      availability.Result
+	fields []string
+}
+
+func projectResult(r availability.Result, fields []string) any {
+	if len(fields) == 0 {
+		return r
+	}
+	return projectedResult{r: r, fields: fields}
+}
+
+func (p projectedResult) MarshalJSON() ([]byte, error) {
+	full, err := json.Marshal(p.r)
+	if err != nil {
+		return nil, err
+	}
+	var byKey map[string]json.RawMessage
+	if err := json.Unmarshal(full, &byKey); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	first := true
+	for _, f := range p.fields {
+		v, ok := byKey[f]
+		if !ok {
+			continue
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(f)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// parseResultTemplate compiles a --template value. Literal "\t" and "\n" are
+// unescaped for shell convenience. Field references are checked by executing
+// the template against a sample result, so typos fail before any lookups.
+func parseResultTemplate(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	tmpl, err := template.New("result").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	// Populate pointer fields so valid paths like .RegistrarLimits.Limit do
+	// not trip nil-pointer errors during the dry run.
+	sample := availability.Result{
+		Registered:      boolPtr(false),
+		Buyable:         boolPtr(false),
+		Premium:         boolPtr(false),
+		Privacy:         boolPtr(false),
+		FirstYearPromo:  boolPtr(false),
+		RegistrarLimits: &registrar.Limits{},
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateWriter renders results with a --template.
+type templateWriter struct {
+	tmpl *template.Template
+}
+
+func (t templateWriter) Write(w io.Writer, results []availability.Result) error {
+	return writeTemplate(w, t.tmpl, results)
+}
+
+func writeTemplate(w io.Writer, tmpl *template.Template, results []availability.Result) error {
+	for _, r := range results {
+		if err := tmpl.Execute(w, r); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden from current output")
+
+// TestResultWriters_Golden pins every registered format's output for a fixed
+// set of results; run with -update after an intended change.
+func TestResultWriters_Golden(t *testing.T) {
+	t.Parallel()
+
+	results := []availability.Result{
+		{Domain: "a.com", TLD: "com", Status: availability.StatusAvailable, Method: availability.MethodRDAP, Confidence: "high", Detail: "rdap 404", CheckedAt: "2026-01-01T00:00:00Z", Registrar: "porkbun", Buyable: boolPtr(true), Price: "10.29", Currency: "USD"},
+		{Domain: "b.com", TLD: "com", Status: availability.StatusTaken, Method: availability.MethodWHOIS, Confidence: "medium", Detail: "whois record found", CheckedAt: "2026-01-01T00:00:00Z", Privacy: boolPtr(true)},
+		{Domain: "c.dev", TLD: "dev", Status: availability.StatusUnknown, Method: availability.MethodNone, Confidence: "low", Error: "timeout", CheckedAt: "2026-01-01T00:00:00Z"},
+	}
+
+	for _, f := range formatNames {
+		var buf bytes.Buffer
+		if err := newResultWriter(f.format, writerOptions{}).Write(&buf, results); err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		path := filepath.Join("testdata", "results."+f.name+".golden")
+		if *updateGolden {
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatalf("write %s: %v", path, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if got := buf.String(); got != string(want) {
+			t.Fatalf("%s output=%q, want %q", f.name, got, want)
+		}
+	}
+}
+
+func TestWriteTemplate_RendersOneLinePerResult(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseResultTemplate(`{{.Domain}}\t{{upper .Status}}\t{{default "-" .Price}}\t{{yesno .Buyable}}`)
+	if err != nil {
+		t.Fatalf("parseResultTemplate: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = writeTemplate(&buf, tmpl, []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable, Price: "10.29", Buyable: boolPtr(true)},
+		{Domain: "b.com", Status: availability.StatusTaken},
+	})
+	if err != nil {
+		t.Fatalf("writeTemplate: %v", err)
+	}
+
+	want := "a.com\tAVAILABLE\t10.29\tyes\nb.com\tTAKEN\t-\t\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output=%q, want %q", got, want)
+	}
+}
+
+func TestParseResultTemplate_RejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		`{{.Domian}}`,
+		`{{.Domain`,
+		`{{nope .Domain}}`,
+	} {
+		_, err := parseResultTemplate(text)
+		if err == nil || !strings.Contains(err.Error(), "invalid --template") {
+			t.Fatalf("parseResultTemplate(%q) err=%v, want invalid --template", text, err)
+		}
+	}
+
+	if _, err := parseResultTemplate(`{{.RegistrarLimits.Limit}} {{join .DomainStatus ","}}`); err != nil {
+		t.Fatalf("parseResultTemplate(nested) err=%v, want nil", err)
+	}
+}
+
+func TestWriteTLDSummary(t *testing.T) {
+	t.Parallel()
+
+	results := []availability.Result{
+		{Domain: "a.io", TLD: "io", Status: availability.StatusAvailable, Buyable: boolPtr(true)},
+		{Domain: "a.com", TLD: "com", Status: availability.StatusTaken},
+		{Domain: "b.com", TLD: "com", Status: availability.StatusAvailable, Buyable: boolPtr(false)},
+		{Input: "bad..input", Status: availability.StatusUnknown},
+		{Domain: "nic.io", TLD: "io", Status: availability.StatusReserved},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTLDSummary(&buf, formatPlain, results); err != nil {
+		t.Fatalf("writeTLDSummary: %v", err)
+	}
+	want := "-\t0\t0\t1\t0\t0\ncom\t1\t1\t0\t0\t0\nio\t1\t0\t0\t1\t1\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("plain=%q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := writeTLDSummary(&buf, formatNDJSON, results); err != nil {
+		t.Fatalf("writeTLDSummary: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"io":{"available":1,"taken":0,"unknown":0,"buyable":1,"reserved":1}`) {
+		t.Fatalf("ndjson=%q, want io counts", buf.String())
+	}
+}
+
+func TestWriteResults_Fields(t *testing.T) {
+	t.Parallel()
+
+	fields, err := parseFields("status, domain,price,status")
+	if err != nil {
+		t.Fatalf("parseFields: %v", err)
+	}
+	results := []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable, Price: "10.29", Method: availability.MethodRDAP},
+		{Domain: "b.com", Status: availability.StatusTaken},
+	}
+
+	var buf bytes.Buffer
+	if err := writeNDJSON(&buf, results, fields, false); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `{"status":"available","domain":"a.com","price":"10.29"}` + "\n" + `{"status":"taken","domain":"b.com"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("ndjson=%q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, results[1:], fields); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, want := buf.String(), `[{"status":"taken","domain":"b.com"}]`+"\n"; got != want {
+		t.Fatalf("json=%q, want %q", got, want)
+	}
+
+	if _, err := parseFields("domain,pricee"); err == nil || !strings.Contains(err.Error(), `"pricee"`) {
+		t.Fatalf("parseFields(unknown) err=%v, want unknown field error", err)
+	}
+}
+
+func TestWritePlain_Columns(t *testing.T) {
+	t.Parallel()
+
+	cols, err := parsePlainColumns("domain, unicode,status,price")
+	if err != nil {
+		t.Fatalf("parsePlainColumns: %v", err)
+	}
+	results := []availability.Result{
+		{Domain: "xn--mnchen-3ya.de", Status: availability.StatusAvailable, Price: "5.00"},
+		{Domain: "a.com", Status: availability.StatusTaken},
+	}
+
+	var buf bytes.Buffer
+	if err := writePlain(&buf, results, cols); err != nil {
+		t.Fatalf("writePlain: %v", err)
+	}
+	want := "xn--mnchen-3ya.de\tmünchen.de\tavailable\t5.00\na.com\ta.com\ttaken\t\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("plain=%q, want %q", got, want)
+	}
+
+	if _, err := parsePlainColumns("domain,tld"); err == nil || !strings.Contains(err.Error(), `"tld"`) {
+		t.Fatalf("parsePlainColumns(unknown) err=%v, want unknown column error", err)
+	}
+}
+
+func TestWriteStatusFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	paths := map[availability.Status]string{
+		availability.StatusAvailable: filepath.Join(dir, "avail.txt"),
+		availability.StatusTaken:     filepath.Join(dir, "taken.txt"),
+		availability.StatusReserved:  filepath.Join(dir, "reserved.txt"),
+	}
+	results := []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable, Method: availability.MethodRDAP, Confidence: "high"},
+		{Domain: "b.com", Status: availability.StatusTaken, Method: availability.MethodRDAP, Confidence: "high"},
+		{Domain: "c.com", Status: availability.StatusAvailable, Method: availability.MethodRDAP, Confidence: "high"},
+		{Domain: "d.com", Status: availability.StatusUnknown, Method: availability.MethodNone, Confidence: "low"},
+	}
+	if err := writeStatusFiles(paths, newResultWriter(formatPlain, writerOptions{plainColumns: []string{"domain"}}), results); err != nil {
+		t.Fatalf("writeStatusFiles: %v", err)
+	}
+
+	for status, want := range map[availability.Status]string{
+		availability.StatusAvailable: "a.com\nc.com\n",
+		availability.StatusTaken:     "b.com\n",
+		availability.StatusReserved:  "",
+	} {
+		b, err := os.ReadFile(paths[status])
+		if err != nil {
+			t.Fatalf("%s: %v", status, err)
+		}
+		if string(b) != want {
+			t.Fatalf("%s file=%q, want %q", status, b, want)
+		}
+	}
+}
+
+// countingWriter records how output reaches it: writes and flushes.
+type countingWriter struct {
+	bytes.Buffer
+	writes, flushes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func (w *countingWriter) Flush() error {
+	w.flushes++
+	return nil
+}
+
+func TestWriteNDJSON_Buffering(t *testing.T) {
+	t.Parallel()
+
+	results := []availability.Result{{Domain: "a.com"}, {Domain: "b.com"}, {Domain: "c.com"}}
+
+	var perLine countingWriter
+	if err := writeNDJSON(&perLine, results, nil, false); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if perLine.writes != 3 || perLine.flushes != 3 {
+		t.Fatalf("unbuffered writes=%d flushes=%d, want 3 each", perLine.writes, perLine.flushes)
+	}
+
+	var buffered countingWriter
+	if err := writeNDJSON(&buffered, results, nil, true); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if buffered.writes != 1 || buffered.flushes != 0 {
+		t.Fatalf("buffered writes=%d flushes=%d, want one write", buffered.writes, buffered.flushes)
+	}
+	if buffered.String() != perLine.String() {
+		t.Fatalf("buffered output=%q, want %q", buffered.String(), perLine.String())
+	}
+}
+
+func TestResolveNDJSONBuffering(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.ndjson"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	for _, tc := range []struct {
+		val  string
+		out  *os.File
+		want bool
+	}{
+		{"auto", f, true},
+		{"auto", w, false},
+		{"true", w, true},
+		{"false", f, false},
+	} {
+		got, err := resolveNDJSONBuffering(tc.val, tc.out)
+		if err != nil || got != tc.want {
+			t.Fatalf("resolveNDJSONBuffering(%q, %s)=%v, %v; want %v", tc.val, tc.out.Name(), got, err, tc.want)
+		}
+	}
+	if _, err := resolveNDJSONBuffering("sometimes", f); err == nil {
+		t.Fatalf("resolveNDJSONBuffering(sometimes): err=nil, want error")
+	}
+}
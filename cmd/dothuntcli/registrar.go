@@ -2,23 +2,74 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/ratelimit"
 	"github.com/benithors/dothuntcli/internal/registrar"
 )
 
-func enrichWithRegistrar(ctx context.Context, reg registrar.Client, concurrency int, results []availability.Result, shouldCheck func(availability.Result) bool) {
+// defaultRegistrarBatchSize is how many domains go into one bulk request for
+// providers implementing registrar.BulkChecker that publish no limit.
+const defaultRegistrarBatchSize = 50
+
+// enrichOptions tunes enrichWithRegistrar.
+type enrichOptions struct {
+	concurrency int
+	limiter     *ratelimit.Bucket // nil means unlimited
+	cache       *registrar.Cache  // nil means always ask the provider
+	batchSize   int               // bulk providers only; 0 means registrarBatchSize's default
+}
+
+// registrarBatchSize returns the bulk request size for bc: requested when
+// set, else the provider's limit, else defaultRegistrarBatchSize.
+func registrarBatchSize(bc registrar.BulkChecker, requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if n := bc.MaxBatchSize(); n > 0 {
+		return n
+	}
+	return defaultRegistrarBatchSize
+}
+
+// enrichWithRegistrar fills registrar fields for results passing shouldCheck.
+// Domains found in the cache are answered from it; every provider request
+// first waits on the limiter.
+func enrichWithRegistrar(ctx context.Context, reg registrar.Client, opts enrichOptions, results []availability.Result, shouldCheck func(availability.Result) bool) {
 	if reg == nil {
 		return
 	}
+	concurrency := opts.concurrency
 	if concurrency <= 0 {
 		concurrency = 4
 	}
+	limiter, cache := opts.limiter, opts.cache
 	if shouldCheck == nil {
 		shouldCheck = func(r availability.Result) bool { return true }
 	}
 
+	var idxs []int
+	for i, r := range results {
+		if r.Domain == "" || r.Error != "" {
+			continue
+		}
+		if !shouldCheck(r) {
+			continue
+		}
+		if dc, ok := cache.Get(reg.Name(), r.Domain); ok {
+			applyDomainCheck(&results[i], reg.Name(), dc, nil)
+			continue
+		}
+		idxs = append(idxs, i)
+	}
+
+	if bc, ok := reg.(registrar.BulkChecker); ok {
+		enrichWithBulkRegistrar(ctx, reg.Name(), bc, concurrency, registrarBatchSize(bc, opts.batchSize), limiter, cache, results, idxs)
+		return
+	}
+
 	type job struct {
 		idx    int
 		domain string
@@ -37,35 +88,22 @@ func enrichWithRegistrar(ctx context.Context, reg registrar.Client, concurrency
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
-				dc, err := reg.CheckDomain(ctx, j.domain)
-				r := &results[j.idx]
-				r.Registrar = reg.Name()
-				if err != nil {
-					r.RegistrarError = err.Error()
+				if err := limiter.Wait(ctx); err != nil {
+					applyDomainCheck(&results[j.idx], reg.Name(), registrar.DomainCheck{}, err)
 					continue
 				}
-				r.Buyable = boolPtr(dc.Buyable)
-				r.Premium = boolPtr(dc.Premium)
-				r.Price = dc.Price
-				r.RegularPrice = dc.RegularPrice
-				r.Currency = dc.Currency
-				r.MinDuration = dc.MinDuration
-				r.FirstYearPromo = boolPtr(dc.FirstYearPromo)
-				r.RegistrarLimits = dc.Limits
-				r.RegistrarError = ""
+				dc, err := reg.CheckDomain(ctx, j.domain)
+				if err == nil {
+					cache.Put(reg.Name(), j.domain, dc)
+				}
+				applyDomainCheck(&results[j.idx], reg.Name(), dc, err)
 			}
 		}()
 	}
 
 	go func() {
-		for i, r := range results {
-			if r.Domain == "" || r.Error != "" {
-				continue
-			}
-			if !shouldCheck(r) {
-				continue
-			}
-			jobs <- job{idx: i, domain: r.Domain}
+		for _, i := range idxs {
+			jobs <- job{idx: i, domain: results[i].Domain}
 		}
 		close(jobs)
 	}()
@@ -73,4 +111,204 @@ func enrichWithRegistrar(ctx context.Context, reg registrar.Client, concurrency
 	wg.Wait()
 }
 
+func enrichWithBulkRegistrar(ctx context.Context, name string, bc registrar.BulkChecker, concurrency, batchSize int, limiter *ratelimit.Bucket, cache *registrar.Cache, results []availability.Result, idxs []int) {
+	batches := make(chan []int)
+	var wg sync.WaitGroup
+
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				domains := make([]string, 0, len(batch))
+				seen := map[string]struct{}{}
+				for _, idx := range batch {
+					d := results[idx].Domain
+					if _, ok := seen[d]; ok {
+						continue
+					}
+					seen[d] = struct{}{}
+					domains = append(domains, d)
+				}
+
+				var checks map[string]registrar.DomainCheck
+				err := limiter.Wait(ctx)
+				if err == nil {
+					checks, err = bc.CheckDomains(ctx, domains)
+				}
+				for _, idx := range batch {
+					r := &results[idx]
+					if err != nil {
+						applyDomainCheck(r, name, registrar.DomainCheck{}, err)
+						continue
+					}
+					dc, ok := checks[r.Domain]
+					if !ok {
+						applyDomainCheck(r, name, registrar.DomainCheck{}, fmt.Errorf("%s: no result for %s", name, r.Domain))
+						continue
+					}
+					cache.Put(name, r.Domain, dc)
+					applyDomainCheck(r, name, dc, nil)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for start := 0; start < len(idxs); start += batchSize {
+			end := min(start+batchSize, len(idxs))
+			batches <- idxs[start:end]
+		}
+		close(batches)
+	}()
+
+	wg.Wait()
+}
+
+// registrarStream enriches results while lookups are still running, so
+// each one can be handed on (--resume state, webhooks) as soon as its
+// registrar answer is in. add never blocks; workers take whatever is queued,
+// up to one bulk request's worth, and call done with each finished result.
+type registrarStream struct {
+	ctx         context.Context
+	reg         registrar.Client
+	opts        enrichOptions
+	shouldCheck func(availability.Result) bool
+	done        func(availability.Result)
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []availability.Result
+	closed   bool
+	enriched map[string]availability.Result // by streamKey
+	wg       sync.WaitGroup
+}
+
+// startRegistrarStream starts opts.concurrency workers. done may be nil and
+// is called from several goroutines.
+func startRegistrarStream(ctx context.Context, reg registrar.Client, opts enrichOptions, shouldCheck func(availability.Result) bool, done func(availability.Result)) *registrarStream {
+	s := &registrarStream{
+		ctx:         ctx,
+		reg:         reg,
+		opts:        opts,
+		shouldCheck: shouldCheck,
+		done:        done,
+		enriched:    make(map[string]availability.Result),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	batchSize := 1
+	if bc, ok := reg.(registrar.BulkChecker); ok {
+		batchSize = registrarBatchSize(bc, opts.batchSize)
+	}
+	workers := opts.concurrency
+	if workers <= 0 {
+		workers = 4
+	}
+	one := opts
+	one.concurrency = 1
+	s.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer s.wg.Done()
+			for {
+				batch := s.take(batchSize)
+				if batch == nil {
+					return
+				}
+				enrichWithRegistrar(s.ctx, s.reg, one, batch, nil)
+				s.mu.Lock()
+				for _, r := range batch {
+					s.enriched[streamKey(r)] = r
+				}
+				s.mu.Unlock()
+				if s.done != nil {
+					for _, r := range batch {
+						s.done(r)
+					}
+				}
+			}
+		}()
+	}
+	return s
+}
+
+// add queues r for enrichment, or passes it straight to done when there is
+// nothing to ask the registrar.
+func (s *registrarStream) add(r availability.Result) {
+	if r.Domain == "" || r.Error != "" || (s.shouldCheck != nil && !s.shouldCheck(r)) {
+		if s.done != nil {
+			s.done(r)
+		}
+		return
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, r)
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+// take waits for queued results and returns up to n of them, or nil once
+// the stream is closed and drained.
+func (s *registrarStream) take(n int) []availability.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.queue) == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if len(s.queue) == 0 {
+		return nil
+	}
+	n = min(n, len(s.queue))
+	batch := make([]availability.Result, n)
+	copy(batch, s.queue)
+	s.queue = s.queue[n:]
+	return batch
+}
+
+// finish waits for the queue to drain and copies the registrar answers into
+// results, matching them up by input.
+func (s *registrarStream) finish(results []availability.Result) {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cond.Broadcast()
+	s.wg.Wait()
+	for i := range results {
+		if r, ok := s.enriched[streamKey(results[i])]; ok {
+			results[i] = r
+		}
+	}
+}
+
+// streamKey identifies a result by what was typed. The checker clears Input
+// when it equals Domain, so the domain stands in then.
+func streamKey(r availability.Result) string {
+	if r.Input != "" {
+		return r.Input
+	}
+	return r.Domain
+}
+
+func applyDomainCheck(r *availability.Result, name string, dc registrar.DomainCheck, err error) {
+	r.Registrar = name
+	if err != nil {
+		r.RegistrarError = err.Error()
+		return
+	}
+	r.Buyable = boolPtr(dc.Buyable)
+	r.Premium = boolPtr(dc.Premium)
+	r.Price = dc.Price
+	r.RegularPrice = dc.RegularPrice
+	r.RenewalPrice = dc.RenewalPrice
+	r.Currency = dc.Currency
+	r.MinDuration = dc.MinDuration
+	r.FirstYearPromo = boolPtr(dc.FirstYearPromo)
+	r.RegistrarLimits = dc.Limits
+	r.RegistrarError = ""
+	if r.Status == availability.StatusAvailable && !dc.Buyable {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("available at the registry, but %s won't sell it", name))
+	}
+}
+
 func boolPtr(v bool) *bool { return &v }
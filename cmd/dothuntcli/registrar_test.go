@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/registrar"
+)
+
+type countingRegistrar struct{ calls atomic.Int32 }
+
+func (c *countingRegistrar) Name() string { return "fake" }
+
+func (c *countingRegistrar) CheckDomain(ctx context.Context, domain string) (registrar.DomainCheck, error) {
+	c.calls.Add(1)
+	return registrar.DomainCheck{Buyable: true, Price: "9.99"}, nil
+}
+
+func TestEnrichWithRegistrar_UsesCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	reg := &countingRegistrar{}
+
+	run := func() []availability.Result {
+		cache := registrar.NewCache(dir, time.Hour)
+		results := []availability.Result{{Domain: "a.com"}, {Domain: "b.com"}}
+		enrichWithRegistrar(context.Background(), reg, enrichOptions{concurrency: 2, cache: cache}, results, nil)
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return results
+	}
+
+	run()
+	results := run()
+	if n := reg.calls.Load(); n != 2 {
+		t.Fatalf("registrar calls=%d, want 2 (second run served from cache)", n)
+	}
+	for _, r := range results {
+		if r.Price != "9.99" || r.Registrar != "fake" {
+			t.Fatalf("%s: price=%q registrar=%q, want cached 9.99 from fake", r.Domain, r.Price, r.Registrar)
+		}
+	}
+}
+
+type recordingBulkRegistrar struct {
+	max     int
+	mu      sync.Mutex
+	batches []int
+}
+
+func (b *recordingBulkRegistrar) Name() string { return "bulk" }
+
+func (b *recordingBulkRegistrar) CheckDomain(ctx context.Context, domain string) (registrar.DomainCheck, error) {
+	return registrar.DomainCheck{}, fmt.Errorf("unexpected single check")
+}
+
+func (b *recordingBulkRegistrar) CheckDomains(ctx context.Context, domains []string) (map[string]registrar.DomainCheck, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(domains))
+	b.mu.Unlock()
+	out := make(map[string]registrar.DomainCheck, len(domains))
+	for _, d := range domains {
+		out[d] = registrar.DomainCheck{Buyable: true}
+	}
+	return out, nil
+}
+
+func (b *recordingBulkRegistrar) MaxBatchSize() int { return b.max }
+
+func TestEnrichWithRegistrar_BatchSize(t *testing.T) {
+	t.Parallel()
+
+	results := func() []availability.Result {
+		out := make([]availability.Result, 7)
+		for i := range out {
+			out[i].Domain = fmt.Sprintf("d%d.com", i)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		max, requested int
+		want           string
+	}{
+		{max: 3, want: "[1 3 3]"},                 // provider limit
+		{max: 3, requested: 2, want: "[1 2 2 2]"}, // explicit size
+		{max: 0, want: "[7]"},                     // no published limit: default 50
+	} {
+		reg := &recordingBulkRegistrar{max: tc.max}
+		enrichWithRegistrar(context.Background(), reg, enrichOptions{concurrency: 1, batchSize: tc.requested}, results(), nil)
+		sort.Ints(reg.batches)
+		if got := fmt.Sprint(reg.batches); got != tc.want {
+			t.Fatalf("max=%d requested=%d: batches=%s, want %s", tc.max, tc.requested, got, tc.want)
+		}
+	}
+}
+
+type unsellableRegistrar struct{}
+
+func (unsellableRegistrar) Name() string { return "fake" }
+
+func (unsellableRegistrar) CheckDomain(ctx context.Context, domain string) (registrar.DomainCheck, error) {
+	return registrar.DomainCheck{Buyable: false}, nil
+}
+
+func TestEnrichWithRegistrar_WarnsWhenAvailableButNotBuyable(t *testing.T) {
+	t.Parallel()
+
+	results := []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable, Warnings: []string{"earlier caveat"}},
+		{Domain: "b.com", Status: availability.StatusUnknown},
+	}
+	enrichWithRegistrar(context.Background(), unsellableRegistrar{}, enrichOptions{concurrency: 1}, results, nil)
+
+	if w := results[0].Warnings; len(w) != 2 || w[0] != "earlier caveat" || !strings.Contains(w[1], "won't sell") {
+		t.Fatalf("a.com Warnings=%q, want earlier caveat plus registrar warning", w)
+	}
+	if w := results[1].Warnings; len(w) != 0 {
+		t.Fatalf("b.com Warnings=%q, want none for an unknown result", w)
+	}
+}
+
+func TestRegistrarStream_EnrichesBeforeDone(t *testing.T) {
+	t.Parallel()
+
+	reg := &countingRegistrar{}
+	var mu sync.Mutex
+	done := map[string]string{}
+	s := startRegistrarStream(context.Background(), reg, enrichOptions{concurrency: 2}, func(r availability.Result) bool {
+		return r.Status == availability.StatusAvailable
+	}, func(r availability.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		done[r.Domain] = r.Price
+	})
+
+	results := []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable},
+		{Input: "B.com", Domain: "b.com", Status: availability.StatusAvailable},
+		{Domain: "c.com", Status: availability.StatusTaken},
+	}
+	for _, r := range results {
+		s.add(r)
+	}
+	s.finish(results)
+
+	if len(done) != 3 || done["a.com"] != "9.99" || done["b.com"] != "9.99" || done["c.com"] != "" {
+		t.Fatalf("done=%v, want a.com and b.com priced, c.com passed through", done)
+	}
+	if results[0].Price != "9.99" || results[1].Price != "9.99" || results[2].Registrar != "" {
+		t.Fatalf("results=%+v, want enrichment copied back", results)
+	}
+	if n := reg.calls.Load(); n != 2 {
+		t.Fatalf("registrar calls=%d, want 2", n)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+// readResumeState returns the domains already recorded in a --resume state
+// file. A missing file is an empty state. Lines that don't parse (e.g. one
+// cut short by a crash) are ignored; their domains are simply checked again.
+func readResumeState(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	done := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var r availability.Result
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil || r.Domain == "" {
+			continue
+		}
+		done[r.Domain] = true
+	}
+	return done, sc.Err()
+}
+
+// skipResumed drops inputs whose normalized domain is in done. Invalid inputs
+// are kept so they're reported again.
+func skipResumed(inputs []string, profile domain.Profile, done map[string]bool) (out []string, skipped int) {
+	out = make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		if ascii, err := domain.NormalizeWithProfile(in, profile); err == nil && done[ascii] {
+			skipped++
+			continue
+		}
+		out = append(out, in)
+	}
+	return out, skipped
+}
+
+// resumeLog appends completed results to a --resume state file, one NDJSON
+// line per result, written as each one finishes. It is safe for concurrent
+// use.
+type resumeLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	err error
+}
+
+func openResumeLog(path string) (*resumeLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	// A crash can leave a partial last line; start on a fresh one so the
+	// next record stays parseable.
+	if st, err := f.Stat(); err == nil && st.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, st.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte("\n")); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return &resumeLog{f: f}, nil
+}
+
+// record appends r unless it should be checked again on resume: unknowns
+// left by a canceled ctx or a transient failure (see retryOnResume). The
+// first write error is kept for Close.
+func (l *resumeLog) record(ctx context.Context, r availability.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil || r.Domain == "" {
+		return
+	}
+	if ctx.Err() != nil && r.Status == availability.StatusUnknown {
+		return
+	}
+	if isInputError(r) || retryOnResume(r) {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		l.err = err
+		return
+	}
+	_, l.err = l.f.Write(append(b, '\n'))
+}
+
+// retryOnResume reports whether r is an unknown whose lookup failed in a way
+// that may clear up (timeouts, rate limits, maintenance, network errors).
+// Unknowns with no error, or with no server to ask, are as final as a
+// verdict gets.
+func retryOnResume(r availability.Result) bool {
+	cat := errorCategory(r)
+	return cat != "" && cat != "no server"
+}
+
+func (l *resumeLog) Close() error {
+	err := l.f.Close()
+	if l.err != nil {
+		return l.err
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+func TestResumeState_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.ndjson")
+	done, err := readResumeState(path)
+	if err != nil || len(done) != 0 {
+		t.Fatalf("missing file: done=%v err=%v, want empty state", done, err)
+	}
+
+	// Simulate a crash mid-write: a complete line, then a partial one.
+	if err := os.WriteFile(path, []byte(`{"domain":"a.com","status":"taken"}`+"\n"+`{"domain":"b.co`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := openResumeLog(path)
+	if err != nil {
+		t.Fatalf("openResumeLog: %v", err)
+	}
+	log.record(context.Background(), availability.Result{Domain: "c.com", Status: availability.StatusAvailable})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	log.record(canceled, availability.Result{Domain: "d.com", Status: availability.StatusUnknown, Error: "context canceled"})
+	log.record(context.Background(), availability.Result{Domain: "e.com", Status: availability.StatusUnknown, RDAPError: "i/o timeout"})
+	log.record(context.Background(), availability.Result{Domain: "f.zz", Status: availability.StatusUnknown, RDAPError: "no RDAP service for tld"})
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	done, err = readResumeState(path)
+	if err != nil {
+		t.Fatalf("readResumeState: %v", err)
+	}
+	if !done["a.com"] || !done["c.com"] || !done["f.zz"] || done["b.co"] || done["d.com"] || done["e.com"] || len(done) != 3 {
+		t.Fatalf("done=%v, want a.com, c.com and f.zz only", done)
+	}
+
+	inputs, skipped := skipResumed([]string{"A.com", "b.com", "c.com", "bad..name"}, domain.ProfileLookup, done)
+	if skipped != 2 || strings.Join(inputs, ",") != "b.com,bad..name" {
+		t.Fatalf("inputs=%v skipped=%d, want b.com,bad..name and 2 skipped", inputs, skipped)
+	}
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"runtime"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/demo"
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/ratelimit"
 	"github.com/benithors/dothuntcli/internal/rdap"
 	"github.com/benithors/dothuntcli/internal/registrar"
+	"github.com/benithors/dothuntcli/internal/registrar/namedotcom"
 	"github.com/benithors/dothuntcli/internal/registrar/porkbun"
+	"github.com/benithors/dothuntcli/internal/resolver"
 	"github.com/benithors/dothuntcli/internal/whois"
 	"github.com/spf13/cobra"
 )
@@ -19,27 +28,77 @@ type config struct {
 	Version string
 
 	// Global flags.
-	VersionFlag          bool
-	Format               string
-	JSON                 bool
-	NDJSON               bool
-	Plain                bool
-	Timeout              time.Duration
-	Concurrency          int
-	NoWHOIS              bool
-	Strict               bool
-	Quiet                bool
-	Verbose              bool
-	Registrar            string
-	RegistrarConcurrency int
+	VersionFlag             bool
+	PrintConfig             bool
+	Format                  string
+	JSON                    bool
+	NDJSON                  bool
+	JSONLinesBuffered       string
+	Plain                   bool
+	Template                string
+	Fields                  string
+	PlainColumns            string
+	Timeout                 time.Duration
+	DNSResolver             string
+	Concurrency             int
+	MaxConcurrency          int
+	Shuffle                 bool
+	Seed                    uint64
+	TLDDelay                time.Duration
+	TLDRatePolicy           string
+	RegistryRate            float64
+	MaxUnknownRatio         float64
+	IDNAProfile             string
+	NoWHOIS                 bool
+	WHOISServers            []string
+	RDAPURLs                []string
+	WHOISBackoff            string
+	WHOISMaxBackoff         time.Duration
+	WHOISFallbackDelay      time.Duration
+	WHOISRefusedCooldown    time.Duration
+	CrossCheck              bool
+	DoubleCheck             bool
+	DNSDisambiguate         bool
+	MethodPolicy            string
+	RDAPAuthRequired        string
+	Strict                  bool
+	Quiet                   bool
+	ErrorJSON               bool
+	Verbose                 bool
+	Registrar               string
+	RegistrarConcurrency    int
+	MaxRegistrarConcurrency int
+	RegistrarRate           float64
+	RegistrarBatchSize      int
+	RegistrarCacheTTL       time.Duration
+	NoRegistrarCache        bool
+	RequireRegistrar        bool
+	DB                      string
+	Demo                    bool
 
 	// Derived runtime state.
-	checker   *availability.Checker
-	outFormat outputFormat
-	registrar registrar.Client
+	rdapClient     *rdap.Client
+	whoisClient    *whois.Client
+	checker        *availability.Checker
+	outFormat      outputFormat
+	ndjsonBuffered bool
+	outTemplate    *template.Template
+	outFields      []string
+	outColumns     []string
+	idnaProfile    domain.Profile
+	registrar      registrar.Client
+	httpClient     *http.Client // nil unless --dns-resolver needs a custom dialer
+
+	registrarLimiter *ratelimit.Bucket
+	registrarCache   *registrar.Cache
+
+	// demoServers backs --demo; closed after the command runs.
+	demoServers *demo.Servers
 }
 
-func newRootCmd(ver string) *cobra.Command {
+// newRootCmd builds the command tree and the config its flags fill in. Call
+// cfg.close once the command has executed, whatever its outcome.
+func newRootCmd(ver string) (*cobra.Command, *config) {
 	cfg := &config{Version: ver}
 
 	root := &cobra.Command{
@@ -62,25 +121,64 @@ dothuntcli --format json --registrar none check example.com
 
 	pf := root.PersistentFlags()
 	pf.BoolVar(&cfg.VersionFlag, "version", false, "Print version and exit")
+	pf.BoolVar(&cfg.PrintConfig, "print-config", false, "Print the effective configuration (flags, env, resolved defaults) as JSON and exit")
 	pf.StringVar(&cfg.Format, "format", "auto", "Output format: auto|table|ndjson|json|plain")
 	pf.BoolVar(&cfg.JSON, "json", false, "Alias for --format json (single JSON array)")
 	pf.BoolVar(&cfg.NDJSON, "ndjson", false, "Alias for --format ndjson (one JSON object per line)")
 	pf.BoolVar(&cfg.NDJSON, "jsonl", false, "Alias for --format ndjson (one JSON object per line)")
+	pf.StringVar(&cfg.JSONLinesBuffered, "json-lines-buffered", "auto", "NDJSON buffering: auto (buffer when stdout is a file, flush each line otherwise)|true|false")
+	pf.Lookup("json-lines-buffered").NoOptDefVal = "true"
 	pf.BoolVar(&cfg.Plain, "plain", false, "Alias for --format plain (stable tab-separated)")
+	pf.StringVar(&cfg.Template, "template", "", "Render each result with a Go text/template, e.g. '{{.Domain}}\\t{{.Status}}'")
+	pf.StringVar(&cfg.Fields, "fields", "", "Comma-separated JSON keys to emit for json/ndjson output, e.g. domain,status,price")
+	pf.StringVar(&cfg.PlainColumns, "plain-columns", "", "Comma-separated columns for plain output: domain,unicode,status,method,confidence,price,score (implies --plain)")
 	pf.DurationVar(&cfg.Timeout, "timeout", 8*time.Second, "Per-request timeout (e.g. 8s, 2s)")
+	pf.StringVar(&cfg.DNSResolver, "dns-resolver", "", "Resolve hostnames via this server instead of the system resolver: https://... (DoH), tls://host (DoT) or host[:port]")
 	pf.IntVar(&cfg.Concurrency, "concurrency", 16, "Max concurrent lookups")
+	pf.IntVar(&cfg.MaxConcurrency, "max-concurrency", 256, "Ceiling for --concurrency; higher values are lowered to it with a warning")
+	pf.BoolVar(&cfg.Shuffle, "shuffle", false, "Check domains in random order to spread load across servers (output order is unchanged)")
+	pf.Uint64Var(&cfg.Seed, "seed", 0, "Seed for --shuffle and --sort random (0 = random)")
+	pf.DurationVar(&cfg.TLDDelay, "tld-delay", 0, "Minimum delay between starting lookups on the same TLD (e.g. 500ms; 0 = off)")
+	pf.StringVar(&cfg.TLDRatePolicy, "tld-rate-policy", "", "File setting per-TLD throttling, e.g. \"de = max_concurrent=1, min_delay=2s\" (* for the rest)")
+	pf.Float64Var(&cfg.RegistryRate, "registry-rate", 0, "Max RDAP+WHOIS requests per second to each registry (TLD), shared by both methods (0 = no limit)")
+	pf.Float64Var(&cfg.MaxUnknownRatio, "max-unknown-ratio", 0, "Abort (exit 1, partial output) once this share of the first 20+ results is unknown, e.g. 0.5 (0 = off)")
+	pf.StringVar(&cfg.IDNAProfile, "idna-profile", "lookup", "How Unicode domains become ASCII: lookup|registration|punycode (see README)")
 	pf.BoolVar(&cfg.NoWHOIS, "no-whois", false, "Disable WHOIS fallback (RDAP only)")
+	pf.StringArrayVar(&cfg.RDAPURLs, "rdap-url", nil, "Route a TLD or suffix to an RDAP base URL first: tld=https://... (repeatable)")
+	pf.StringArrayVar(&cfg.WHOISServers, "whois-server", nil, "Override the WHOIS server for a TLD or suffix: tld=host[:port] (repeatable)")
+	pf.StringVar(&cfg.WHOISBackoff, "whois-backoff", "exponential", "WHOIS retry backoff: constant|linear|exponential")
+	pf.DurationVar(&cfg.WHOISMaxBackoff, "whois-max-backoff", 2*time.Second, "Cap on the delay between WHOIS retries")
+	pf.DurationVar(&cfg.WHOISFallbackDelay, "whois-fallback-delay", 300*time.Millisecond, "Wait this long on a WHOIS server's first address family before also trying the other (IPv4/IPv6); 0 = dial one at a time")
+	pf.DurationVar(&cfg.WHOISRefusedCooldown, "whois-refused-cooldown", 10*time.Minute, "Skip a WHOIS server for this long after it refuses two connections in a row (0 = never skip)")
+	pf.BoolVar(&cfg.CrossCheck, "cross-check", false, "Also query WHOIS after a definitive RDAP answer; report disagreements as unknown")
+	pf.BoolVar(&cfg.DoubleCheck, "double-check", false, "Confirm available results with a second RDAP mirror and/or WHOIS; downgrade to unknown unless one agrees")
+	pf.BoolVar(&cfg.DNSDisambiguate, "dns-disambiguate", false, "Report unknown results as taken (low confidence) when the domain or its www host resolves in DNS")
+	pf.StringVar(&cfg.MethodPolicy, "method-policy", "", "File mapping TLDs (or *) to lookup order, e.g. \"de = whois,rdap\"")
+	pf.StringVar(&cfg.RDAPAuthRequired, "rdap-auth-required", "", "Comma-separated TLDs whose RDAP servers block anonymous queries (skip RDAP for them)")
 	pf.BoolVar(&cfg.Strict, "strict", false, "Exit non-zero if any result is UNKNOWN/error")
 	pf.BoolVarP(&cfg.Quiet, "quiet", "q", false, "Suppress non-essential stderr output")
+	pf.BoolVar(&cfg.ErrorJSON, "error-json", false, "On failure, write {\"error\":...,\"code\":N} to stderr instead of plain text")
 	pf.BoolVarP(&cfg.Verbose, "verbose", "v", false, "Verbose stderr output (diagnostics)")
-	pf.StringVar(&cfg.Registrar, "registrar", "auto", "Registrar provider for buyable checks: auto|none|porkbun")
+	pf.StringVar(&cfg.Registrar, "registrar", "auto", "Registrar provider for buyable checks: auto|none|porkbun|namecom")
+	pf.BoolVar(&cfg.RequireRegistrar, "require-registrar", false, "Fail instead of skipping registrar enrichment when no registrar is configured")
 	pf.IntVar(&cfg.RegistrarConcurrency, "registrar-concurrency", 4, "Max concurrent registrar checks")
+	// Registrar APIs throttle far below what RDAP and WHOIS tolerate.
+	pf.IntVar(&cfg.MaxRegistrarConcurrency, "max-registrar-concurrency", 32, "Ceiling for --registrar-concurrency; higher values are lowered to it with a warning")
+	pf.Float64Var(&cfg.RegistrarRate, "registrar-rate", 0, "Max registrar requests per second across all workers and providers (0 = no extra limit)")
+	pf.IntVar(&cfg.RegistrarBatchSize, "registrar-batch-size", 0, "Domains per bulk registrar request (0 = provider default; Name.com allows up to 50)")
+	pf.DurationVar(&cfg.RegistrarCacheTTL, "registrar-cache-ttl", time.Hour, "How long cached registrar answers are reused across runs")
+	pf.BoolVar(&cfg.NoRegistrarCache, "no-registrar-cache", false, "Always ask the registrar; don't read or write the registrar cache")
+	pf.StringVar(&cfg.DB, "db", "", "SQLite file to record check results in (read back with history)")
+	pf.BoolVar(&cfg.Demo, "demo", false, "Answer from built-in mock RDAP/WHOIS servers and a stub registrar instead of the internet (try example.com, anything.dev)")
 
 	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if cfg.VersionFlag {
 			fmt.Fprintf(os.Stdout, "dothuntcli %s (%s/%s)\n", cfg.Version, runtime.GOOS, runtime.GOARCH)
 			return errExit0
 		}
+		if err := applyEnvDefaults(cmd); err != nil {
+			return usageErr(cmd, err)
+		}
 
 		formatStr := strings.ToLower(strings.TrimSpace(cfg.Format))
 		if formatStr == "" {
@@ -119,41 +217,260 @@ dothuntcli --format json --registrar none check example.com
 			return usageErr(cmd, err)
 		}
 		cfg.outFormat = outFormat
+		cfg.ndjsonBuffered, err = resolveNDJSONBuffering(cfg.JSONLinesBuffered, os.Stdout)
+		if err != nil {
+			return usageErr(cmd, err)
+		}
 
-		rdapClient := rdap.NewClient(rdap.Options{
-			Timeout: cfg.Timeout,
-			Verbose: cfg.Verbose && !cfg.Quiet,
-		})
+		if cfg.Template != "" {
+			if formatStr != "auto" {
+				return usageErr(cmd, fmt.Errorf("do not combine --template with --format/--json/--ndjson/--plain"))
+			}
+			cfg.outTemplate, err = parseResultTemplate(cfg.Template)
+			if err != nil {
+				return usageErr(cmd, err)
+			}
+		}
+
+		if cfg.Concurrency < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --concurrency %d (must be >= 0)", cfg.Concurrency))
+		}
+		if cfg.RegistrarConcurrency < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --registrar-concurrency %d (must be >= 0)", cfg.RegistrarConcurrency))
+		}
+		if cfg.MaxConcurrency < 1 {
+			return usageErr(cmd, fmt.Errorf("invalid --max-concurrency %d (must be >= 1)", cfg.MaxConcurrency))
+		}
+		if cfg.MaxRegistrarConcurrency < 1 {
+			return usageErr(cmd, fmt.Errorf("invalid --max-registrar-concurrency %d (must be >= 1)", cfg.MaxRegistrarConcurrency))
+		}
+		// Thousands of parallel lookups only get the run blocked by every
+		// server involved, so clamp instead of trusting a typo.
+		if cfg.Concurrency > cfg.MaxConcurrency {
+			if !cfg.Quiet {
+				fmt.Fprintf(os.Stderr, "Lowering --concurrency %d to %d (raise --max-concurrency to allow more)\n", cfg.Concurrency, cfg.MaxConcurrency)
+			}
+			cfg.Concurrency = cfg.MaxConcurrency
+		}
+		if cfg.RegistrarConcurrency > cfg.MaxRegistrarConcurrency {
+			if !cfg.Quiet {
+				fmt.Fprintf(os.Stderr, "Lowering --registrar-concurrency %d to %d (raise --max-registrar-concurrency to allow more)\n", cfg.RegistrarConcurrency, cfg.MaxRegistrarConcurrency)
+			}
+			cfg.RegistrarConcurrency = cfg.MaxRegistrarConcurrency
+		}
+
+		if cfg.TLDDelay < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --tld-delay %v (must be >= 0)", cfg.TLDDelay))
+		}
+		cfg.idnaProfile, err = domain.ParseProfile(cfg.IDNAProfile)
+		if err != nil {
+			return usageErr(cmd, fmt.Errorf("invalid --idna-profile: %w", err))
+		}
+		if cfg.MaxUnknownRatio < 0 || cfg.MaxUnknownRatio >= 1 {
+			return usageErr(cmd, fmt.Errorf("invalid --max-unknown-ratio %v (must be >= 0 and < 1)", cfg.MaxUnknownRatio))
+		}
+		if cfg.RegistryRate < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --registry-rate %v (must be >= 0)", cfg.RegistryRate))
+		}
+
+		if strings.TrimSpace(cfg.PlainColumns) != "" {
+			if cfg.Template != "" || (formatStr != "auto" && formatStr != "plain") {
+				return usageErr(cmd, fmt.Errorf("--plain-columns applies to plain output only"))
+			}
+			cfg.outColumns, err = parsePlainColumns(cfg.PlainColumns)
+			if err != nil {
+				return usageErr(cmd, err)
+			}
+			cfg.outFormat = formatPlain
+		}
+
+		if strings.TrimSpace(cfg.Fields) != "" {
+			if cfg.Template != "" || formatStr == "table" || formatStr == "plain" || len(cfg.outColumns) > 0 {
+				return usageErr(cmd, fmt.Errorf("--fields applies to json/ndjson output only"))
+			}
+			cfg.outFields, err = parseFields(cfg.Fields)
+			if err != nil {
+				return usageErr(cmd, err)
+			}
+		}
+
+		rdapOverrides, err := parseRDAPURLOverrides(cfg.RDAPURLs)
+		if err != nil {
+			return usageErr(cmd, err)
+		}
+		res, err := resolver.New(cfg.DNSResolver, nil)
+		if err != nil {
+			return usageErr(cmd, fmt.Errorf("invalid --dns-resolver: %w", err))
+		}
+		// Every outgoing connection (RDAP, WHOIS, registrars) resolves
+		// names through --dns-resolver when set.
+		dialer := &net.Dialer{Resolver: res}
+		var httpc *http.Client
+		if res != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.DialContext = dialer.DialContext
+			httpc = &http.Client{Timeout: cfg.Timeout, Transport: transport}
+		}
+		cfg.httpClient = httpc
+
+		rdapOpts := rdap.Options{
+			Timeout:      cfg.Timeout,
+			HTTPClient:   httpc,
+			Verbose:      cfg.Verbose && !cfg.Quiet,
+			URLOverrides: rdapOverrides,
+		}
+		var lookupHost func(ctx context.Context, host string) ([]string, error)
+		if res != nil {
+			lookupHost = res.LookupHost
+		}
+		var whoisDial func(ctx context.Context, network, addr string) (net.Conn, error)
+		if cfg.Demo {
+			// The mock registries change port every run, so nothing they
+			// answer may reach the on-disk caches real runs rely on.
+			if cfg.demoServers, err = demo.Start(demo.Default); err != nil {
+				return err
+			}
+			rdapOpts.BootstrapURL = cfg.demoServers.BootstrapURL
+			rdapOpts.NoDiskCache = true
+			rdapOpts.HTTPClient = nil
+			whoisDial = cfg.demoServers.Dial
+			lookupHost = cfg.demoServers.LookupHost
+		}
+
+		// One limiter paces both methods so a WHOIS fallback doesn't land on
+		// a registry right after the RDAP request that just failed there.
+		registryLimiter := availability.NewRegistryLimiter(cfg.RegistryRate)
+		rdapOpts.Limiter = registryLimiter
+		rdapClient := rdap.NewClient(rdapOpts)
+		whoisOverrides, err := parseWHOISServerOverrides(cfg.WHOISServers)
+		if err != nil {
+			return usageErr(cmd, err)
+		}
+		backoffStrategy, err := whois.ParseBackoffStrategy(cfg.WHOISBackoff)
+		if err != nil {
+			return usageErr(cmd, fmt.Errorf("invalid --whois-backoff: %w", err))
+		}
+		if cfg.WHOISFallbackDelay < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --whois-fallback-delay %v (must be >= 0)", cfg.WHOISFallbackDelay))
+		}
+		whoisFallback := cfg.WHOISFallbackDelay
+		if whoisFallback == 0 {
+			whoisFallback = -1 // whois.Options treats 0 as the default
+		}
+		if cfg.WHOISRefusedCooldown < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --whois-refused-cooldown %v (must be >= 0)", cfg.WHOISRefusedCooldown))
+		}
+		whoisCooldown := cfg.WHOISRefusedCooldown
+		if whoisCooldown == 0 {
+			whoisCooldown = -1
+		}
 		whoisClient := whois.NewClient(whois.Options{
-			Timeout: cfg.Timeout,
-			Verbose: cfg.Verbose && !cfg.Quiet,
+			Timeout:         cfg.Timeout,
+			Resolver:        res,
+			FallbackDelay:   whoisFallback,
+			Verbose:         cfg.Verbose && !cfg.Quiet,
+			ServerOverrides: whoisOverrides,
+			BackoffStrategy: backoffStrategy,
+			MaxBackoff:      cfg.WHOISMaxBackoff,
+			Limiter:         registryLimiter,
+			RefusedCooldown: whoisCooldown,
+			DialFunc:        whoisDial,
+			NoDiskCache:     cfg.Demo,
+			// Cross-checks and double-checks may ask about the same
+			// domain again in one run.
+			MemoizeWithinRun: true,
 		})
 
+		var methodPolicy map[string][]availability.Method
+		if path := strings.TrimSpace(cfg.MethodPolicy); path != "" {
+			methodPolicy, err = readMethodPolicyFile(path)
+			if err != nil {
+				return usageErr(cmd, err)
+			}
+		}
+
+		var tldRates map[string]availability.TLDRate
+		if path := strings.TrimSpace(cfg.TLDRatePolicy); path != "" {
+			tldRates, err = readTLDRatePolicyFile(path, availability.TLDRate{MinDelay: cfg.TLDDelay})
+			if err != nil {
+				return usageErr(cmd, err)
+			}
+		}
+
+		var rdapAuthRequired map[string]bool
+		for _, in := range splitCommaList(cfg.RDAPAuthRequired) {
+			tld, err := domain.NormalizeTLD(in)
+			if err != nil {
+				return usageErr(cmd, fmt.Errorf("invalid --rdap-auth-required entry %q: %w", in, err))
+			}
+			if rdapAuthRequired == nil {
+				rdapAuthRequired = make(map[string]bool)
+			}
+			rdapAuthRequired[tld] = true
+		}
+
+		seed := cfg.Seed
+		if cfg.Shuffle && seed == 0 {
+			seed = uint64(time.Now().UnixNano())
+		}
+
+		cfg.rdapClient = rdapClient
+		cfg.whoisClient = whoisClient
 		cfg.checker = availability.NewChecker(availability.Options{
-			RDAP:        rdapClient,
-			WHOIS:       whoisClient,
-			NoWHOIS:     cfg.NoWHOIS,
-			Timeout:     cfg.Timeout,
-			Concurrency: max(1, cfg.Concurrency),
-			Verbose:     cfg.Verbose && !cfg.Quiet,
-			Quiet:       cfg.Quiet,
+			RDAP:             rdapClient,
+			WHOIS:            whoisClient,
+			NoWHOIS:          cfg.NoWHOIS,
+			CrossCheck:       cfg.CrossCheck,
+			DoubleCheck:      cfg.DoubleCheck,
+			DNSDisambiguate:  cfg.DNSDisambiguate,
+			LookupHost:       lookupHost,
+			MethodPolicy:     methodPolicy,
+			RDAPAuthRequired: rdapAuthRequired,
+			Timeout:          cfg.Timeout,
+			Concurrency:      max(1, cfg.Concurrency),
+			Shuffle:          cfg.Shuffle,
+			Seed:             seed,
+			TLDDelay:         cfg.TLDDelay,
+			TLDRates:         tldRates,
+			IDNAProfile:      cfg.idnaProfile,
+			MaxUnknownRatio:  cfg.MaxUnknownRatio,
+			Verbose:          cfg.Verbose && !cfg.Quiet,
+			Quiet:            cfg.Quiet,
 		})
 
 		choice := strings.ToLower(strings.TrimSpace(cfg.Registrar))
+		if cfg.Demo && choice != "" && choice != "auto" && choice != "none" {
+			return usageErr(cmd, fmt.Errorf("--demo uses its own stub registrar (use --registrar auto or none)"))
+		}
 		switch choice {
 		case "", "auto":
-			creds, err := loadPorkbunCredentials()
-			if err != nil {
-				if cfg.Verbose && !cfg.Quiet {
-					fmt.Fprintf(os.Stderr, "Porkbun credentials unavailable: %v\n", err)
-				}
+			if cfg.Demo {
+				cfg.registrar = demo.NewRegistrar(demo.Default)
 				break
 			}
-			if creds.APIKey != "" && creds.SecretAPIKey != "" {
+			creds, err := loadPorkbunCredentials()
+			if err != nil && cfg.Verbose && !cfg.Quiet {
+				fmt.Fprintf(os.Stderr, "Porkbun credentials unavailable: %v\n", err)
+			}
+			if err == nil && creds.APIKey != "" && creds.SecretAPIKey != "" {
 				c, err := porkbun.NewClient(porkbun.Options{
 					APIKey:       creds.APIKey,
 					SecretAPIKey: creds.SecretAPIKey,
 					Timeout:      cfg.Timeout,
+					HTTPClient:   httpc,
+				})
+				if err != nil {
+					return err
+				}
+				cfg.registrar = c
+				break
+			}
+			if nc := loadNameComCredentials(); nc.complete() {
+				c, err := namedotcom.NewClient(namedotcom.Options{
+					Username:   nc.Username,
+					Token:      nc.Token,
+					Timeout:    cfg.Timeout,
+					HTTPClient: httpc,
 				})
 				if err != nil {
 					return err
@@ -174,19 +491,77 @@ dothuntcli --format json --registrar none check example.com
 				APIKey:       creds.APIKey,
 				SecretAPIKey: creds.SecretAPIKey,
 				Timeout:      cfg.Timeout,
+				HTTPClient:   httpc,
+			})
+			if err != nil {
+				return err
+			}
+			cfg.registrar = c
+		case "namecom":
+			nc := loadNameComCredentials()
+			if !nc.complete() {
+				return usageErr(cmd, fmt.Errorf("missing Name.com API credentials (set %s and %s)", nameComUsernameEnv, nameComTokenEnv))
+			}
+			c, err := namedotcom.NewClient(namedotcom.Options{
+				Username:   nc.Username,
+				Token:      nc.Token,
+				Timeout:    cfg.Timeout,
+				HTTPClient: httpc,
 			})
 			if err != nil {
 				return err
 			}
 			cfg.registrar = c
 		default:
-			return usageErr(cmd, fmt.Errorf("unknown registrar %q (use auto|none|porkbun)", cfg.Registrar))
+			return usageErr(cmd, fmt.Errorf("unknown registrar %q (use auto|none|porkbun|namecom)", cfg.Registrar))
+		}
+		if cfg.RegistrarRate < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --registrar-rate %v (must be >= 0)", cfg.RegistrarRate))
+		}
+		cfg.registrarLimiter = ratelimit.NewBucket(cfg.RegistrarRate, 1)
+		if cfg.RegistrarCacheTTL < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --registrar-cache-ttl %v (must be >= 0)", cfg.RegistrarCacheTTL))
+		}
+		if cfg.RegistrarBatchSize < 0 {
+			return usageErr(cmd, fmt.Errorf("invalid --registrar-batch-size %d (must be >= 0)", cfg.RegistrarBatchSize))
+		}
+		if bc, ok := cfg.registrar.(registrar.BulkChecker); ok {
+			if limit := bc.MaxBatchSize(); limit > 0 && cfg.RegistrarBatchSize > limit {
+				return usageErr(cmd, fmt.Errorf("invalid --registrar-batch-size %d (%s allows at most %d)", cfg.RegistrarBatchSize, cfg.registrar.Name(), limit))
+			}
+		}
+		if cfg.registrar != nil && !cfg.NoRegistrarCache && !cfg.Demo {
+			cfg.registrarCache = registrar.NewCache("", cfg.RegistrarCacheTTL)
+		}
+
+		if cfg.RequireRegistrar && cfg.registrar == nil {
+			return usageErr(cmd, fmt.Errorf("--require-registrar: no registrar configured (set %s/%s or %s/%s, or pass --registrar porkbun|namecom)",
+				porkbunAPIKeyEnv, porkbunSecretAPIKeyEnv, nameComUsernameEnv, nameComTokenEnv))
+		}
+
+		if cfg.PrintConfig {
+			if err := printConfig(os.Stdout, cmd, cfg); err != nil {
+				return &cliError{Code: 1, Err: fmt.Errorf("failed to write output: %w", err), Cmd: cmd}
+			}
+			return errExit0
 		}
 
 		return nil
 	}
-
 	root.AddCommand(newCheckCmd(cfg))
+	root.AddCommand(newWarmCacheCmd(cfg))
+	root.AddCommand(newHistoryCmd(cfg))
+	root.AddCommand(newDoctorCmd(cfg))
+	root.AddCommand(newPricingCmd(cfg))
 
-	return root
+	return root, cfg
+}
+
+// close stops what PersistentPreRunE started. It lives outside the command
+// because PersistentPostRun is skipped when RunE fails.
+func (cfg *config) close() {
+	if cfg.demoServers != nil {
+		cfg.demoServers.Close()
+		cfg.demoServers = nil
+	}
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+// maxSuggestDistance caps how many edits apart a taken label and its
+// suggested alternative may be; shorter labels allow fewer (half their
+// length), so "ab" is never matched to "xy".
+const maxSuggestDistance = 3
+
+// attachNearestAvailable sets NearestAvailable on every taken result to the
+// available result from the same run whose label is closest by edit distance.
+// Ties go to the same TLD, then the higher score, then input order. Only
+// results already checked are compared; nothing is looked up.
+func attachNearestAvailable(results []availability.Result) {
+	var avail []int
+	for i, r := range results {
+		if r.Status == availability.StatusAvailable {
+			avail = append(avail, i)
+		}
+	}
+	if len(avail) == 0 {
+		return
+	}
+
+	for i := range results {
+		r := &results[i]
+		if r.Status != availability.StatusTaken {
+			continue
+		}
+		label := resultLabel(*r)
+		limit := min(maxSuggestDistance, len(label)/2)
+		best, bestDist := -1, limit+1
+		for _, j := range avail {
+			d := boundedLevenshtein(label, resultLabel(results[j]), min(bestDist, limit))
+			if d > limit {
+				continue
+			}
+			if best < 0 || d < bestDist || (d == bestDist && betterSuggestion(*r, results[j], results[best])) {
+				best, bestDist = j, d
+			}
+		}
+		if best >= 0 {
+			r.NearestAvailable = results[best].Domain
+		}
+	}
+}
+
+// betterSuggestion reports whether a beats b as the alternative to taken when
+// both are equally close.
+func betterSuggestion(taken, a, b availability.Result) bool {
+	if sa, sb := a.TLD == taken.TLD, b.TLD == taken.TLD; sa != sb {
+		return sa
+	}
+	return a.Score > b.Score
+}
+
+// resultLabel is the label a result was checked for: everything before the
+// TLD.
+func resultLabel(r availability.Result) string {
+	if r.Label != "" {
+		return r.Label
+	}
+	if r.TLD != "" {
+		return strings.TrimSuffix(r.Domain, "."+r.TLD)
+	}
+	label, _, _ := strings.Cut(r.Domain, ".")
+	return label
+}
+
+// boundedLevenshtein returns the edit distance between a and b, or limit+1
+// as soon as it is bound to exceed limit.
+func boundedLevenshtein(a, b string, limit int) int {
+	if len(a)-len(b) > limit || len(b)-len(a) > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	if prev[len(b)] > limit {
+		return limit + 1
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestAttachNearestAvailable(t *testing.T) {
+	t.Parallel()
+
+	results := []availability.Result{
+		{Domain: "example.com", Label: "example", TLD: "com", Status: availability.StatusTaken},
+		{Domain: "exampel.io", Label: "exampel", TLD: "io", Status: availability.StatusAvailable},
+		{Domain: "examples.com", Label: "examples", TLD: "com", Status: availability.StatusAvailable},
+		{Domain: "xamples.com", Label: "xamples", TLD: "com", Status: availability.StatusAvailable, Score: 9},
+		{Domain: "ab.com", Label: "ab", TLD: "com", Status: availability.StatusTaken},
+		{Domain: "xy.com", Label: "xy", TLD: "com", Status: availability.StatusAvailable},
+		{Domain: "hunt.dev", Label: "hunt", TLD: "dev", Status: availability.StatusUnknown},
+	}
+	attachNearestAvailable(results)
+
+	// examples.com (1 edit, same TLD) beats exampel.io (2 edits) and
+	// xamples.com (2 edits, higher score).
+	if got := results[0].NearestAvailable; got != "examples.com" {
+		t.Fatalf("example.com nearest=%q, want examples.com", got)
+	}
+	if got := results[4].NearestAvailable; got != "" {
+		t.Fatalf("ab.com nearest=%q, want none (2 edits on a 2-letter label)", got)
+	}
+	for _, i := range []int{1, 6} {
+		if got := results[i].NearestAvailable; got != "" {
+			t.Fatalf("%s nearest=%q, want only taken results annotated", results[i].Domain, got)
+		}
+	}
+}
+
+func TestBoundedLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"kitten", "sitting", 5, 3},
+		{"kitten", "sitting", 2, 3},
+		{"same", "same", 0, 0},
+		{"", "abc", 3, 3},
+		{"a", "abcdef", 2, 3},
+	} {
+		if got := boundedLevenshtein(tc.a, tc.b, tc.limit); got != tc.want {
+			t.Fatalf("boundedLevenshtein(%q, %q, %d)=%d, want %d", tc.a, tc.b, tc.limit, got, tc.want)
+		}
+	}
+}
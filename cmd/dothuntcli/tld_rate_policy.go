@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+// readTLDRatePolicyFile parses a per-TLD throttling file:
+//
+//	# tld = max_concurrent=N, min_delay=DURATION
+//	de = max_concurrent=1, min_delay=2s
+//	com = max_concurrent=8
+//	* = min_delay=250ms
+//
+// A setting left out of a line takes its value from defaults.
+func readTLDRatePolicyFile(path string, defaults availability.TLDRate) (map[string]availability.TLDRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	policy := map[string]availability.TLDRate{}
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tld rate policy line %d in %s: want tld=max_concurrent=N,min_delay=D", lineNo, path)
+		}
+
+		tld := strings.TrimSpace(key)
+		if tld != "*" {
+			tld, err = domain.NormalizeTLD(tld)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tld rate policy line %d in %s: %w", lineNo, path, err)
+			}
+		}
+		if _, dup := policy[tld]; dup {
+			return nil, fmt.Errorf("invalid tld rate policy line %d in %s: %q listed twice", lineNo, path, tld)
+		}
+
+		rate := defaults
+		settings := splitCommaList(val)
+		if len(settings) == 0 {
+			return nil, fmt.Errorf("invalid tld rate policy line %d in %s: no settings for %q", lineNo, path, tld)
+		}
+		for _, s := range settings {
+			name, v, ok := strings.Cut(s, "=")
+			name, v = strings.TrimSpace(name), strings.TrimSpace(v)
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("invalid tld rate policy line %d in %s: %q is not name=value", lineNo, path, s)
+			case name == "max_concurrent":
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("invalid tld rate policy line %d in %s: max_concurrent %q (must be an integer >= 1)", lineNo, path, v)
+				}
+				rate.MaxConcurrent = n
+			case name == "min_delay":
+				d, err := time.ParseDuration(v)
+				if err != nil || d < 0 {
+					return nil, fmt.Errorf("invalid tld rate policy line %d in %s: min_delay %q (must be a duration >= 0, e.g. 500ms)", lineNo, path, v)
+				}
+				rate.MinDelay = d
+			default:
+				return nil, fmt.Errorf("invalid tld rate policy line %d in %s: unknown setting %q (use max_concurrent|min_delay)", lineNo, path, name)
+			}
+		}
+		policy[tld] = rate
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read tld rate policy %s: %w", path, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestReadTLDRatePolicyFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rates")
+	writeCredentialsFile(t, path, `
+# .de bans fast clients
+.DE = max_concurrent=1, min_delay=2s
+com = max_concurrent=8
+* = min_delay=0
+`)
+
+	got, err := readTLDRatePolicyFile(path, availability.TLDRate{MinDelay: 500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("readTLDRatePolicyFile: %v", err)
+	}
+	if de := got["de"]; de.MaxConcurrent != 1 || de.MinDelay != 2*time.Second {
+		t.Fatalf("de=%+v, want 1 concurrent, 2s apart", de)
+	}
+	if com := got["com"]; com.MaxConcurrent != 8 || com.MinDelay != 500*time.Millisecond {
+		t.Fatalf("com=%+v, want 8 concurrent and the default delay", com)
+	}
+	if all, ok := got["*"]; !ok || all.MinDelay != 0 {
+		t.Fatalf("*=%+v, want explicit zero delay", all)
+	}
+}
+
+func TestReadTLDRatePolicyFile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		"de = max_concurrent=0\n",
+		"de = min_delay=fast\n",
+		"de = burst=3\n",
+		"de = max_concurrent=1\nDE = min_delay=1s\n",
+		"de\n",
+	} {
+		path := filepath.Join(t.TempDir(), "rates")
+		writeCredentialsFile(t, path, body)
+		if _, err := readTLDRatePolicyFile(path, availability.TLDRate{}); err == nil || !strings.Contains(err.Error(), "line ") {
+			t.Fatalf("%q: err=%v, want line-numbered error", body, err)
+		}
+	}
+}
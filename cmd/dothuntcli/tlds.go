@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+// unlistedTLDs returns the distinct TLDs of inputs that aren't in the ICANN
+// section of the public suffix list, in input order. Invalid inputs are left
+// for the checker to report.
+func unlistedTLDs(inputs []string, profile domain.Profile) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, in := range inputs {
+		ascii, err := domain.NormalizeWithProfile(in, profile)
+		if err != nil {
+			continue
+		}
+		tld := ascii[strings.LastIndexByte(ascii, '.')+1:]
+		if seen[tld] {
+			continue
+		}
+		seen[tld] = true
+		if !domain.IsICANNTLD(tld) {
+			out = append(out, tld)
+		}
+	}
+	return out
+}
+
+// dropTLDs removes inputs whose TLD is in tlds and returns the rest, plus an
+// unknown result for each dropped input so it still shows up in the output.
+// Invalid inputs are kept for the checker to report.
+func dropTLDs(inputs []string, profile domain.Profile, tlds map[string]bool) (kept []string, dropped []availability.Result) {
+	for _, in := range inputs {
+		ascii, err := domain.NormalizeWithProfile(in, profile)
+		if err != nil {
+			kept = append(kept, in)
+			continue
+		}
+		i := strings.LastIndexByte(ascii, '.')
+		if !tlds[ascii[i+1:]] {
+			kept = append(kept, in)
+			continue
+		}
+		r := availability.Result{
+			Input:      strings.TrimSpace(in),
+			Domain:     ascii,
+			Label:      ascii[:i],
+			TLD:        ascii[i+1:],
+			Status:     availability.StatusUnknown,
+			Method:     availability.MethodNone,
+			Confidence: "low",
+			Error:      fmt.Sprintf("unknown TLD %q", ascii[i+1:]),
+			Detail:     "skipped: tld not delegated (--allow-unknown-tlds checks it anyway)",
+			CheckedAt:  time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		if r.Input == ascii {
+			r.Input = ""
+		}
+		dropped = append(dropped, r)
+	}
+	return kept, dropped
+}
+
+// readTLDList reads one TLD per line (blank lines and # comments skipped, a
+// leading dot allowed), normalized to ASCII and de-duplicated in input order.
+func readTLDList(r io.Reader) ([]string, error) {
+	lines, err := domain.ReadLines(r)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		tld, err := domain.NormalizeTLD(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLD %q: %w", line, err)
+		}
+		if !seen[tld] {
+			seen[tld] = true
+			out = append(out, tld)
+		}
+	}
+	return out, nil
+}
+
+// joinLabelsAndTLDs returns every label.tld combination, all TLDs of the
+// first label before the next.
+func joinLabelsAndTLDs(labels, tlds []string) []string {
+	out := make([]string, 0, len(labels)*len(tlds))
+	for _, label := range labels {
+		for _, tld := range tlds {
+			out = append(out, label+"."+tld)
+		}
+	}
+	return out
+}
+
+// knownTLDs is the set of TLDs with an RDAP service, plus any routed by
+// --rdap-url or --whois-server; it covers TLDs newer than the embedded public
+// suffix list and private test TLDs.
+func knownTLDs(ctx context.Context, cfg *config) map[string]bool {
+	known := make(map[string]bool)
+	if cfg.rdapClient != nil {
+		if tlds, err := cfg.rdapClient.TLDs(ctx); err == nil {
+			for _, tld := range tlds {
+				known[tld] = true
+			}
+		}
+	}
+	rdapOverrides, _ := parseRDAPURLOverrides(cfg.RDAPURLs)
+	whoisOverrides, _ := parseWHOISServerOverrides(cfg.WHOISServers)
+	for _, m := range []map[string]string{rdapOverrides, whoisOverrides} {
+		for suffix := range m {
+			known[suffix[strings.LastIndexByte(suffix, '.')+1:]] = true
+		}
+	}
+	return known
+}
+
+// unknownTLDMessage explains an unknown TLD, with the closest candidate when
+// it is within two edits.
+func unknownTLDMessage(tld string, candidates []string) string {
+	if s := suggestTLD(tld, candidates); s != "" {
+		return fmt.Sprintf("unknown TLD %q (did you mean %q?)", tld, s)
+	}
+	return fmt.Sprintf("unknown TLD %q", tld)
+}
+
+// suggestTLD returns the candidate closest to tld by edit distance, or "" if
+// none is within two edits. Ties go to a candidate of the same length (a
+// mistyped letter is likelier than a dropped one, so .con suggests .com over
+// .co), then to the earliest.
+func suggestTLD(tld string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		d := levenshtein(tld, c)
+		if d < bestDist || (d == bestDist && len(c) == len(tld) && len(best) != len(tld)) {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein is the edit distance between a and b, counted in bytes (TLDs
+// are ASCII).
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
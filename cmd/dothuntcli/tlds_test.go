@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+func TestUnlistedTLDs(t *testing.T) {
+	t.Parallel()
+
+	got := unlistedTLDs([]string{"a.com", "b.con", "c.CON", "d.example", "bad..name", "e.de"}, domain.ProfileLookup)
+	if strings.Join(got, ",") != "con,example" {
+		t.Fatalf("unlistedTLDs=%v, want [con example]", got)
+	}
+}
+
+func TestDropTLDs(t *testing.T) {
+	t.Parallel()
+
+	kept, dropped := dropTLDs([]string{"a.com", "b.zip2", "C.ZIP2", "bad..name", "d.old"}, domain.ProfileLookup, map[string]bool{"zip2": true, "old": true})
+	if strings.Join(kept, ",") != "a.com,bad..name" {
+		t.Fatalf("kept=%v, want [a.com bad..name]", kept)
+	}
+	var got []string
+	for _, r := range dropped {
+		if r.Status != availability.StatusUnknown || r.Error == "" {
+			t.Fatalf("dropped %s: status=%q error=%q, want unknown with an error", r.Domain, r.Status, r.Error)
+		}
+		got = append(got, r.Input+"|"+r.Domain+"|"+r.TLD)
+	}
+	if want := "|b.zip2|zip2,C.ZIP2|c.zip2|zip2,|d.old|old"; strings.Join(got, ",") != want {
+		t.Fatalf("dropped=%v, want %s", got, want)
+	}
+}
+
+func TestReadTLDList(t *testing.T) {
+	t.Parallel()
+
+	got, err := readTLDList(strings.NewReader("com\n.IO\n\n# mine\nde # germany\ncom\n"))
+	if err != nil || strings.Join(got, ",") != "com,io,de" {
+		t.Fatalf("readTLDList=%v, %v; want [com io de]", got, err)
+	}
+	if _, err := readTLDList(strings.NewReader("com\nco.uk\n")); err == nil {
+		t.Fatalf("readTLDList(co.uk): err=nil, want error")
+	}
+	if got := joinLabelsAndTLDs([]string{"a", "b"}, []string{"com", "io"}); strings.Join(got, ",") != "a.com,a.io,b.com,b.io" {
+		t.Fatalf("joinLabelsAndTLDs=%v", got)
+	}
+}
+
+func TestUnknownTLDMessage(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{"co", "com", "net", "org"}
+	for tld, want := range map[string]string{
+		"con":    `unknown TLD "con" (did you mean "com"?)`,
+		"ogr":    `unknown TLD "ogr" (did you mean "org"?)`,
+		"zzzzzz": `unknown TLD "zzzzzz"`,
+	} {
+		if got := unknownTLDMessage(tld, candidates); got != want {
+			t.Fatalf("unknownTLDMessage(%q)=%q, want %q", tld, got, want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"com", "com", 0},
+		{"con", "com", 1},
+		{"cm", "com", 1},
+		{"ocm", "com", 2},
+		{"", "net", 3},
+	} {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Fatalf("levenshtein(%q, %q)=%d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/url"
 	"os"
 	"strings"
 
+	"github.com/benithors/dothuntcli/internal/availability"
 	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/whois"
 	"golang.org/x/term"
 )
 
-func readDomainsFromArgsAndStdin(args []string, stdin *os.File) ([]string, error) {
+// readDomainsFromArgsAndStdin collects domains from args and, when stdin is not
+// a terminal, from stdin via readStdin (domain.ReadLines if nil).
+func readDomainsFromArgsAndStdin(args []string, stdin *os.File, readStdin func(io.Reader) ([]string, error)) ([]string, error) {
 	var out []string
 
 	for _, a := range args {
@@ -24,7 +32,10 @@ func readDomainsFromArgsAndStdin(args []string, stdin *os.File) ([]string, error
 		return out, nil
 	}
 
-	stdinDomains, err := domain.ReadLines(stdin)
+	if readStdin == nil {
+		readStdin = domain.ReadLines
+	}
+	stdinDomains, err := readStdin(stdin)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +43,131 @@ func readDomainsFromArgsAndStdin(args []string, stdin *os.File) ([]string, error
 	return out, nil
 }
 
+// dedupeDomains drops inputs that normalize to an already-seen domain, keeping
+// the first occurrence. Inputs that fail normalization are compared verbatim.
+func dedupeDomains(inputs []string, profile domain.Profile) (out []string, dropped int) {
+	out = make([]string, 0, len(inputs))
+	seen := make(map[string]struct{}, len(inputs))
+	for _, in := range inputs {
+		key, err := domain.NormalizeWithProfile(in, profile)
+		if err != nil {
+			key = strings.TrimSpace(in)
+		}
+		if _, ok := seen[key]; ok {
+			dropped++
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, in)
+	}
+	return out, dropped
+}
+
+// stripWWWInputs replaces inputs that normalize to "www.<domain>" with the
+// bare domain, calling onStrip for each changed input.
+func stripWWWInputs(inputs []string, profile domain.Profile, onStrip func(in, stripped string)) []string {
+	out := make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		ascii, err := domain.NormalizeWithProfile(in, profile)
+		if err != nil {
+			out = append(out, in)
+			continue
+		}
+		stripped, ok := domain.StripWWW(ascii)
+		if !ok {
+			out = append(out, in)
+			continue
+		}
+		if onStrip != nil {
+			onStrip(in, stripped)
+		}
+		out = append(out, stripped)
+	}
+	return out
+}
+
+// reduceToBaseDomains rewrites each input that normalizes to a subdomain into
+// its registrable domain, calling onReduce for every rewritten input. Inputs
+// that fail normalization are passed through for the checker to report.
+func reduceToBaseDomains(inputs []string, profile domain.Profile, onReduce func(in, base string)) []string {
+	out := make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		ascii, err := domain.NormalizeWithProfile(in, profile)
+		if err != nil {
+			out = append(out, in)
+			continue
+		}
+		base, err := domain.Registrable(ascii)
+		if err != nil || base == ascii {
+			out = append(out, in)
+			continue
+		}
+		if onReduce != nil {
+			onReduce(in, base)
+		}
+		out = append(out, base)
+	}
+	return out
+}
+
+// parseWHOISServerOverrides parses repeated --whois-server "tld=host[:port]"
+// values.
+func parseWHOISServerOverrides(values []string) (map[string]string, error) {
+	return parseTLDOverrides("--whois-server", "tld=host[:port]", values, func(server string) error {
+		_, err := whois.ServerAddr(server)
+		return err
+	})
+}
+
+// isInputError reports whether r failed because its input isn't a valid
+// domain, as opposed to a lookup that failed.
+func isInputError(r availability.Result) bool {
+	return r.Error != "" && r.Detail == "invalid input"
+}
+
+// shuffleResults puts results in a random order derived from seed, so the
+// same seed gives the same order for the same results.
+func shuffleResults(results []availability.Result, seed uint64) {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	rng.Shuffle(len(results), func(i, j int) { results[i], results[j] = results[j], results[i] })
+}
+
+// parseRDAPURLOverrides parses repeated --rdap-url "tld=https://..." values.
+func parseRDAPURLOverrides(values []string) (map[string]string, error) {
+	return parseTLDOverrides("--rdap-url", "tld=https://...", values, func(raw string) error {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("want an absolute http(s) URL")
+		}
+		return nil
+	})
+}
+
+// parseTLDOverrides parses "tld=value" flag values keyed by lower-case TLD or
+// public suffix, checking each value with validate.
+func parseTLDOverrides(flag, usage string, values []string, validate func(string) error) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.Trim(strings.ToLower(strings.TrimSpace(key)), ".")
+		value = strings.TrimSpace(value)
+		if !ok || key == "" || value == "" || strings.ContainsAny(key, " /:") {
+			return nil, fmt.Errorf("invalid %s %q (use %s)", flag, v, usage)
+		}
+		if err := validate(value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", flag, v, err)
+		}
+		out[key] = value
+	}
+	return out, nil
+}
+
 func splitCommaList(s string) []string {
 	parts := strings.Split(s, ",")
 	out := make([]string, 0, len(parts))
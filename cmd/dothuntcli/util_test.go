@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+)
+
+func TestDedupeDomains(t *testing.T) {
+	t.Parallel()
+
+	got, dropped := dedupeDomains([]string{
+		"Example.com",
+		"https://example.com/path",
+		"other.org",
+		"bad..input",
+		"example.com.",
+		"bad..input",
+	}, domain.ProfileLookup)
+	if want := "Example.com,other.org,bad..input"; strings.Join(got, ",") != want {
+		t.Fatalf("dedupeDomains=%v, want %s", got, want)
+	}
+	if dropped != 3 {
+		t.Fatalf("dropped=%d, want 3", dropped)
+	}
+}
+
+func TestReduceToBaseDomains(t *testing.T) {
+	t.Parallel()
+
+	var reduced []string
+	got := reduceToBaseDomains([]string{
+		"www.example.co.uk",
+		"example.co.uk",
+		"https://shop.example.com/cart",
+		"co.uk",
+		"bad..input",
+	}, domain.ProfileLookup, func(in, base string) {
+		reduced = append(reduced, in+"->"+base)
+	})
+
+	if want := "example.co.uk,example.co.uk,example.com,co.uk,bad..input"; strings.Join(got, ",") != want {
+		t.Fatalf("reduceToBaseDomains=%v, want %s", got, want)
+	}
+	if want := "www.example.co.uk->example.co.uk,https://shop.example.com/cart->example.com"; strings.Join(reduced, ",") != want {
+		t.Fatalf("reduced=%v, want %s", reduced, want)
+	}
+}
+
+func TestParseWHOISServerOverrides(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseWHOISServerOverrides([]string{".COM.AU=mirror.test:4343", "de = whois.denic.de"})
+	if err != nil {
+		t.Fatalf("parseWHOISServerOverrides: %v", err)
+	}
+	if got["com.au"] != "mirror.test:4343" || got["de"] != "whois.denic.de" {
+		t.Fatalf("overrides=%v, want normalized keys", got)
+	}
+
+	for _, bad := range []string{"de", "=whois.denic.de", "de=", "de=host:70000"} {
+		if _, err := parseWHOISServerOverrides([]string{bad}); err == nil {
+			t.Fatalf("parseWHOISServerOverrides(%q): err=nil, want error", bad)
+		}
+	}
+}
+
+func TestParseRDAPURLOverrides(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseRDAPURLOverrides([]string{"DE=https://rdap.example.test/v1/"})
+	if err != nil {
+		t.Fatalf("parseRDAPURLOverrides: %v", err)
+	}
+	if got["de"] != "https://rdap.example.test/v1/" {
+		t.Fatalf("overrides=%v, want de entry", got)
+	}
+
+	for _, bad := range []string{"de", "de=rdap.example.test", "de=ftp://x", "=https://x"} {
+		if _, err := parseRDAPURLOverrides([]string{bad}); err == nil {
+			t.Fatalf("parseRDAPURLOverrides(%q): err=nil, want error", bad)
+		}
+	}
+}
+
+func TestShuffleResults_Seeded(t *testing.T) {
+	t.Parallel()
+
+	order := func(seed uint64) string {
+		var results []availability.Result
+		for _, d := range []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com", "g.com", "h.com"} {
+			results = append(results, availability.Result{Domain: d})
+		}
+		shuffleResults(results, seed)
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Domain)
+		}
+		return strings.Join(got, ",")
+	}
+
+	if a, b := order(42), order(42); a != b {
+		t.Fatalf("same seed gave %s and %s", a, b)
+	}
+	if a, b := order(42), order(7); a == b {
+		t.Fatalf("seeds 42 and 7 both gave %s", a)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+// webhookConcurrency bounds how many webhook POSTs are in flight at once.
+const webhookConcurrency = 4
+
+// webhookQueueSize is how many results may wait for a free worker. Beyond
+// that, results are dropped (and counted as failed) rather than slowing the
+// lookups down.
+const webhookQueueSize = 1024
+
+// webhookAttempts is how many times a POST is tried when the endpoint
+// answers 5xx or can't be reached.
+const webhookAttempts = 3
+
+// parseWebhookOn returns the predicate for --webhook-on: available (not
+// known to be unbuyable), buyable (registrar confirmed) or all.
+func parseWebhookOn(s string) (func(availability.Result) bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "available":
+		return func(r availability.Result) bool {
+			return r.Status == availability.StatusAvailable && (r.Buyable == nil || *r.Buyable)
+		}, nil
+	case "buyable":
+		return func(r availability.Result) bool { return r.Buyable != nil && *r.Buyable }, nil
+	case "all":
+		return func(availability.Result) bool { return true }, nil
+	default:
+		return nil, fmt.Errorf("invalid --webhook-on %q (use available|buyable|all)", s)
+	}
+}
+
+// validateWebhookURL accepts absolute http(s) URLs only.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid --webhook %q (want an http:// or https:// URL)", raw)
+	}
+	return nil
+}
+
+// webhookPoster POSTs results as JSON to one URL from a bounded pool of
+// workers, retrying 5xx answers and network errors with backoff.
+type webhookPoster struct {
+	url     string
+	client  *http.Client
+	backoff time.Duration
+
+	ctx  context.Context
+	jobs chan availability.Result
+	wg   sync.WaitGroup
+
+	mu       sync.Mutex
+	sent     int
+	failed   int
+	firstErr error
+}
+
+// newWebhookPoster starts the workers; call close to drain them. A nil
+// client uses one with the given timeout.
+func newWebhookPoster(ctx context.Context, rawURL string, client *http.Client, timeout time.Duration) *webhookPoster {
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	p := &webhookPoster{
+		url:     rawURL,
+		client:  client,
+		backoff: 500 * time.Millisecond,
+		ctx:     ctx,
+		jobs:    make(chan availability.Result, webhookQueueSize),
+	}
+	for range webhookConcurrency {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for r := range p.jobs {
+				err := p.post(r)
+				p.mu.Lock()
+				if err != nil {
+					p.failed++
+					if p.firstErr == nil {
+						p.firstErr = err
+					}
+				} else {
+					p.sent++
+				}
+				p.mu.Unlock()
+			}
+		}()
+	}
+	return p
+}
+
+// enqueue queues r for a worker without blocking. When the queue is full
+// r is dropped and counted as a failure.
+func (p *webhookPoster) enqueue(r availability.Result) {
+	select {
+	case p.jobs <- r:
+	default:
+		p.mu.Lock()
+		p.failed++
+		if p.firstErr == nil {
+			p.firstErr = fmt.Errorf("%s: webhook queue full, dropped", r.Domain)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// close waits for queued posts and reports how they went.
+func (p *webhookPoster) close() (sent, failed int, firstErr error) {
+	close(p.jobs)
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sent, p.failed, p.firstErr
+}
+
+func (p *webhookPoster) post(r availability.Result) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	backoff := p.backoff
+	var lastErr error
+	for attempt := range webhookAttempts {
+		if attempt > 0 {
+			t := time.NewTimer(backoff)
+			select {
+			case <-p.ctx.Done():
+				t.Stop()
+				return p.ctx.Err()
+			case <-t.C:
+			}
+			backoff *= 2
+		}
+		retry, err := p.postOnce(body)
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s: %w", r.Domain, err)
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// postOnce sends one request; retry reports whether a failure is worth
+// another attempt.
+func (p *webhookPoster) postOnce(body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("user-agent", "dothuntcli/webhook")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return p.ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	switch {
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("http %d", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	return false, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestWebhookPoster_RetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res availability.Result
+		if err := json.NewDecoder(r.Body).Decode(&res); err != nil || r.Header.Get("content-type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		hits[res.Domain]++
+		n := hits[res.Domain]
+		mu.Unlock()
+		switch {
+		case res.Domain == "flaky.com" && n == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case res.Domain == "rejected.com":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	p := newWebhookPoster(context.Background(), srv.URL, srv.Client(), time.Second)
+	p.backoff = time.Millisecond
+	for _, d := range []string{"ok.com", "flaky.com", "rejected.com"} {
+		p.enqueue(availability.Result{Domain: d, Status: availability.StatusAvailable})
+	}
+	sent, failed, err := p.close()
+	if sent != 2 || failed != 1 || err == nil || !strings.Contains(err.Error(), "rejected.com: http 403") {
+		t.Fatalf("sent=%d failed=%d err=%v, want 2 sent and rejected.com failed", sent, failed, err)
+	}
+	if hits["flaky.com"] != 2 || hits["rejected.com"] != 1 {
+		t.Fatalf("hits=%v, want flaky.com retried once and 4xx not retried", hits)
+	}
+}
+
+func TestWebhookPoster_EnqueueNeverBlocks(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	p := newWebhookPoster(context.Background(), srv.URL, srv.Client(), time.Minute)
+	extra := 10
+	start := time.Now()
+	for range webhookConcurrency + webhookQueueSize + extra {
+		p.enqueue(availability.Result{Domain: "a.com"})
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("enqueue took %v with every worker stuck, want no blocking", d)
+	}
+	close(release)
+	_, failed, err := p.close()
+	// Workers may not have picked up their first job yet, so up to
+	// webhookConcurrency more can be dropped.
+	if failed < extra || failed > extra+webhookConcurrency || err == nil || !strings.Contains(err.Error(), "queue full") {
+		t.Fatalf("failed=%d err=%v, want about %d dropped", failed, err, extra)
+	}
+}
+
+func TestParseWebhookOn(t *testing.T) {
+	t.Parallel()
+
+	yes, no := true, false
+	results := []availability.Result{
+		{Domain: "a.com", Status: availability.StatusAvailable},
+		{Domain: "b.com", Status: availability.StatusAvailable, Buyable: &yes},
+		{Domain: "c.com", Status: availability.StatusAvailable, Buyable: &no},
+		{Domain: "d.com", Status: availability.StatusTaken},
+	}
+	for on, want := range map[string]string{
+		"available": "a.com,b.com",
+		"buyable":   "b.com",
+		"all":       "a.com,b.com,c.com,d.com",
+	} {
+		pred, err := parseWebhookOn(on)
+		if err != nil {
+			t.Fatalf("parseWebhookOn(%q): %v", on, err)
+		}
+		var got []string
+		for _, r := range results {
+			if pred(r) {
+				got = append(got, r.Domain)
+			}
+		}
+		if strings.Join(got, ",") != want {
+			t.Fatalf("--webhook-on %s matched %v, want %s", on, got, want)
+		}
+	}
+	if _, err := parseWebhookOn("taken"); err == nil {
+		t.Fatalf("parseWebhookOn(taken): err=nil, want error")
+	}
+	if err := validateWebhookURL("ftp://example.com/hook"); err == nil {
+		t.Fatalf("validateWebhookURL(ftp): err=nil, want error")
+	}
+}
+
+func TestRun_CheckWebhook(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	var mu sync.Mutex
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res availability.Result
+		_ = json.NewDecoder(r.Body).Decode(&res)
+		mu.Lock()
+		posted = append(posted, res.Domain)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	// Posted as each result is priced, then straight from the checker.
+	for _, reg := range []string{"auto", "none"} {
+		mu.Lock()
+		posted = nil
+		mu.Unlock()
+		got := runWithArgsCaptured(t, "--demo", "--registrar", reg, "--plain", "check", "--webhook", srv.URL, "example.com", "dothunt-demo.com", "dothunt-demo.io")
+		if got.code != 0 {
+			t.Fatalf("--registrar %s: exit=%d stderr=%q, want 0", reg, got.code, got.stderr)
+		}
+		mu.Lock()
+		sort.Strings(posted)
+		if strings.Join(posted, ",") != "dothunt-demo.com,dothunt-demo.io" {
+			t.Fatalf("--registrar %s: posted=%v, want the two available domains", reg, posted)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestRun_CheckWebhookSkipsCanceledLookups(t *testing.T) {
+	isolatePorkbunCredentialSources(t)
+
+	var mu sync.Mutex
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res availability.Result
+		_ = json.NewDecoder(r.Body).Decode(&res)
+		mu.Lock()
+		posted = append(posted, res.Domain+" "+string(res.Status)+" "+res.Error)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	got := runWithArgsCaptured(t, "--demo", "--registrar", "none", "--concurrency", "1", "--plain", "check", "--limit-available", "1", "--webhook", srv.URL, "--webhook-on", "all", "dothunt-a.com", "dothunt-b.com", "dothunt-c.com", "dothunt-d.com")
+	if got.code != 0 {
+		t.Fatalf("exit=%d stderr=%q, want 0", got.code, got.stderr)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range posted {
+		if strings.Contains(p, "canceled") {
+			t.Fatalf("posted=%v, want no results for lookups --limit-available canceled", posted)
+		}
+	}
+	if len(posted) == 0 || len(posted) > 2 {
+		t.Fatalf("posted=%v, want only the domains actually checked", posted)
+	}
+}
@@ -2,10 +2,16 @@ package availability
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/benithors/dothuntcli/internal/clock"
 	"github.com/benithors/dothuntcli/internal/domain"
 	"github.com/benithors/dothuntcli/internal/rdap"
 	"github.com/benithors/dothuntcli/internal/registrar"
@@ -17,7 +23,11 @@ type Status string
 const (
 	StatusAvailable Status = "available"
 	StatusTaken     Status = "taken"
-	StatusUnknown   Status = "unknown"
+	// StatusReserved is a name the registry withholds from registration
+	// (reserved, premium-withheld or prohibited); it is not registered but
+	// cannot be bought either.
+	StatusReserved Status = "reserved"
+	StatusUnknown  Status = "unknown"
 )
 
 type Method string
@@ -26,6 +36,9 @@ const (
 	MethodRDAP  Method = "rdap"
 	MethodWHOIS Method = "whois"
 	MethodNone  Method = "none"
+	// MethodDNS marks a taken verdict inferred from DNS (DNSDisambiguate);
+	// it is never definitive.
+	MethodDNS Method = "dns"
 )
 
 type Result struct {
@@ -40,6 +53,26 @@ type Result struct {
 	Method     Method `json:"method"`
 	Confidence string `json:"confidence"`
 	Detail     string `json:"detail,omitempty"`
+	Conflict   string `json:"conflict,omitempty"`
+	// Warnings are caveats about the input or the verdict, one sentence
+	// each (an IDN TLD, a truncated WHOIS response, ...).
+	Warnings []string `json:"warnings,omitempty"`
+	// ConfirmedBy lists the second sources that agreed with an available
+	// verdict under DoubleCheck.
+	ConfirmedBy []string `json:"confirmed_by,omitempty"`
+
+	// Registry status codes (RDAP "status" or WHOIS "Domain Status") when the
+	// deciding lookup returned them.
+	DomainStatus []string `json:"domain_status,omitempty"`
+	// Variants lists the IDN variant names RDAP reports for the domain,
+	// with their relation (bundled, blocked, ...).
+	Variants []string `json:"variants,omitempty"`
+	// NearestAvailable is, for a taken domain, the available domain from the
+	// same run whose label is the fewest edits away, if one is close.
+	NearestAvailable string `json:"nearest_available,omitempty"`
+	// Privacy is true when RDAP shows redacted or privacy-protected registrant
+	// details; nil when the registry gave no indication.
+	Privacy    *bool  `json:"privacy,omitempty"`
 	Error      string `json:"error,omitempty"`
 	CheckedAt  string `json:"checked_at"`
 	DurationMs int64  `json:"duration_ms"`
@@ -50,12 +83,26 @@ type Result struct {
 	RDAPError  string `json:"rdap_error,omitempty"`
 	RDAPURL    string `json:"rdap_url,omitempty"`
 	RDAPCode   int    `json:"rdap_http_status,omitempty"`
+	// RDAPAttempts/WHOISAttempts count requests made, including failover and
+	// retries, to spot domains that needed more than one try.
+	RDAPAttempts int `json:"rdap_attempts,omitempty"`
+	// RDAPBytes/RDAPLatencyMs (and the WHOIS pair) are only set with
+	// Options.Verbose: response size and server round-trip time of the
+	// deciding request, for spotting slow or chatty servers.
+	RDAPBytes     int   `json:"rdap_bytes,omitempty"`
+	RDAPLatencyMs int64 `json:"rdap_latency_ms,omitempty"`
 
-	WHOISStatus  string `json:"whois_status,omitempty"`
-	WHOISReason  string `json:"whois_reason,omitempty"`
-	WHOISError   string `json:"whois_error,omitempty"`
-	WHOISServer  string `json:"whois_server,omitempty"`
-	WHOISPattern string `json:"whois_pattern,omitempty"`
+	WHOISStatus    string `json:"whois_status,omitempty"`
+	WHOISReason    string `json:"whois_reason,omitempty"`
+	WHOISError     string `json:"whois_error,omitempty"`
+	WHOISServer    string `json:"whois_server,omitempty"`
+	WHOISPattern   string `json:"whois_pattern,omitempty"`
+	WHOISTruncated bool   `json:"whois_truncated,omitempty"`
+	WHOISAttempts  int    `json:"whois_attempts,omitempty"`
+	WHOISBytes     int    `json:"whois_bytes,omitempty"`
+	WHOISLatencyMs int64  `json:"whois_latency_ms,omitempty"`
+	// WHOISServerChain is the referral path to WHOISServer (Verbose only).
+	WHOISServerChain []string `json:"whois_server_chain,omitempty"`
 
 	// Registrar enrichment (optional; only present when a registrar client was used).
 	Registrar       string            `json:"registrar,omitempty"`
@@ -63,6 +110,7 @@ type Result struct {
 	Premium         *bool             `json:"premium,omitempty"`
 	Price           string            `json:"price,omitempty"`
 	RegularPrice    string            `json:"regular_price,omitempty"`
+	RenewalPrice    string            `json:"renewal_price,omitempty"`
 	Currency        string            `json:"currency,omitempty"`
 	MinDuration     int               `json:"min_duration,omitempty"`
 	FirstYearPromo  *bool             `json:"first_year_promo,omitempty"`
@@ -78,20 +126,116 @@ type Options struct {
 	Concurrency int
 	Verbose     bool
 	Quiet       bool
+
+	// CrossCheck runs the next method even after a definitive answer and
+	// reports disagreements as unknown instead of trusting the first one.
+	CrossCheck bool
+
+	// DoubleCheck re-verifies available verdicts against a second RDAP mirror
+	// and/or WHOIS; available stands only if one of them agrees and none
+	// says taken, otherwise the result becomes unknown with a Conflict.
+	DoubleCheck bool
+
+	// MethodPolicy maps a TLD (or "*" for the default) to the ordered lookup
+	// methods to use for it. Unlisted TLDs use RDAP then WHOIS.
+	MethodPolicy map[string][]Method
+
+	// RDAPAuthRequired lists TLDs whose RDAP servers reject anonymous domain
+	// queries; RDAP is skipped for them. TLDs that answer 403 during a run are
+	// added automatically so later domains go straight to the next method.
+	RDAPAuthRequired map[string]bool
+
+	// Shuffle dispatches inputs in a random order (seeded by Seed) so runs of
+	// domains on one TLD don't all hit the same server back to back. Results
+	// are still returned in input order.
+	Shuffle bool
+	Seed    uint64
+
+	// TLDDelay is the minimum spacing between the starts of lookups for
+	// domains on the same TLD, whatever method answers them.
+	TLDDelay time.Duration
+	// TLDRates sets throttling per TLD, with "*" for TLDs not listed. A
+	// matching entry replaces TLDDelay for that TLD.
+	TLDRates map[string]TLDRate
+	// Clock times the TLDDelay spacing (default clock.Real).
+	Clock clock.Clock
+
+	// IDNAProfile controls how Unicode inputs are converted (default
+	// domain.ProfileLookup).
+	IDNAProfile domain.Profile
+
+	// MaxUnknownRatio aborts CheckDomainsFunc once more than this share of
+	// the finished results are unknown, after at least UnknownSample of them
+	// (default 20). 0 disables the check.
+	MaxUnknownRatio float64
+	UnknownSample   int
+
+	// DNSDisambiguate resolves the apex and www of domains RDAP/WHOIS left
+	// unknown; if either resolves the domain is reported taken with low
+	// confidence, unless the suffix has wildcard DNS. Definitive answers are
+	// never touched. LookupHost does the resolving (default
+	// net.DefaultResolver.LookupHost).
+	DNSDisambiguate bool
+	LookupHost      func(ctx context.Context, host string) ([]string, error)
+}
+
+// ErrTooManyUnknown is returned (wrapped) by CheckDomainsFunc when
+// MaxUnknownRatio trips, which usually means the network or an upstream is
+// failing every lookup.
+var ErrTooManyUnknown = errors.New("too many unknown results")
+
+// TLDRate is how hard one registry may be pushed: at most MaxConcurrent
+// lookups in flight (0 = no cap beyond Concurrency) with starts at least
+// MinDelay apart.
+type TLDRate struct {
+	MaxConcurrent int
+	MinDelay      time.Duration
 }
 
 type Checker struct {
 	opts Options
+
+	// rdapForbidden holds TLDs whose RDAP server answered 403 in this run.
+	rdapForbidden sync.Map
+	// dnsWildcard caches, per registry suffix, whether a random label under
+	// it resolves, which makes DNS useless as a registration signal.
+	dnsWildcard sync.Map
+
+	tldMu   sync.Mutex
+	tldNext map[string]time.Time
+	tldSems map[string]chan struct{}
 }
 
 func NewChecker(opts Options) *Checker {
 	if opts.Concurrency <= 0 {
 		opts.Concurrency = 16
 	}
+	if opts.UnknownSample <= 0 {
+		opts.UnknownSample = 20
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real{}
+	}
 	return &Checker{opts: opts}
 }
 
 func (c *Checker) CheckDomains(ctx context.Context, inputs []string) []Result {
+	results, _ := c.CheckDomainsFunc(ctx, inputs, nil)
+	return results
+}
+
+// CheckDomainsFunc is CheckDomains that also calls fn with each result as it
+// completes (in completion order, from a single goroutine), so callers can
+// persist progress before the whole batch is done. fn may be nil.
+//
+// When MaxUnknownRatio trips, the remaining lookups are canceled and only the
+// results finished before that are returned (in input order), together with
+// an error wrapping ErrTooManyUnknown.
+func (c *Checker) CheckDomainsFunc(ctx context.Context, inputs []string, fn func(Result)) ([]Result, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	type job struct {
 		idx   int
 		input string
@@ -115,15 +259,20 @@ func (c *Checker) CheckDomains(ctx context.Context, inputs []string) []Result {
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
+				tld := c.tldOf(j.input)
+				// A canceled wait leaves checkOne to report ctx's error.
+				release := c.enterTLD(ctx, tld)
+				_ = c.waitTLD(ctx, tld)
 				r := c.checkOne(ctx, j.input)
+				release()
 				results <- out{idx: j.idx, res: r}
 			}
 		}()
 	}
 
 	go func() {
-		for idx, input := range inputs {
-			jobs <- job{idx: idx, input: input}
+		for _, idx := range c.dispatchOrder(len(inputs)) {
+			jobs <- job{idx: idx, input: inputs[idx]}
 		}
 		close(jobs)
 		wg.Wait()
@@ -131,10 +280,131 @@ func (c *Checker) CheckDomains(ctx context.Context, inputs []string) []Result {
 	}()
 
 	outSlice := make([]Result, len(inputs))
+	var finished, unknown int
+	var tripErr error
+	var kept []bool
 	for r := range results {
+		if tripErr != nil {
+			// Canceled by the breaker; not a real answer.
+			continue
+		}
 		outSlice[r.idx] = r.res
+		if fn != nil {
+			fn(r.res)
+		}
+		if c.opts.MaxUnknownRatio <= 0 || parent.Err() != nil {
+			// Lookups the caller canceled say nothing about upstream health.
+			continue
+		}
+		if kept == nil {
+			kept = make([]bool, len(inputs))
+		}
+		kept[r.idx] = true
+		finished++
+		if r.res.Status == StatusUnknown {
+			unknown++
+		}
+		if finished >= c.opts.UnknownSample && float64(unknown)/float64(finished) > c.opts.MaxUnknownRatio {
+			tripErr = fmt.Errorf("%w: %d of %d finished lookups were unknown", ErrTooManyUnknown, unknown, finished)
+			cancel()
+		}
+	}
+	if tripErr == nil {
+		return outSlice, nil
+	}
+
+	partial := make([]Result, 0, finished)
+	for i, ok := range kept {
+		if ok {
+			partial = append(partial, outSlice[i])
+		}
+	}
+	return partial, tripErr
+}
+
+// dispatchOrder returns the order in which input indices are handed to workers.
+func (c *Checker) dispatchOrder(n int) []int {
+	if c.opts.Shuffle {
+		return rand.New(rand.NewPCG(c.opts.Seed, c.opts.Seed)).Perm(n)
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// tldOf returns input's TLD, or "" when input doesn't normalize.
+func (c *Checker) tldOf(input string) string {
+	ascii, err := domain.NormalizeWithProfile(input, c.opts.IDNAProfile)
+	if err != nil {
+		return ""
 	}
-	return outSlice
+	_, tld := splitDomain(ascii)
+	return tld
+}
+
+// rateFor returns the throttling for tld: its TLDRates entry, else the "*"
+// entry, else TLDDelay alone.
+func (c *Checker) rateFor(tld string) TLDRate {
+	if r, ok := c.opts.TLDRates[tld]; ok {
+		return r
+	}
+	if r, ok := c.opts.TLDRates["*"]; ok {
+		return r
+	}
+	return TLDRate{MinDelay: c.opts.TLDDelay}
+}
+
+// enterTLD takes one of tld's MaxConcurrent lookup slots, waiting for one
+// to free up, and returns the func that gives it back. Without a cap (or
+// once ctx is done) it returns at once.
+func (c *Checker) enterTLD(ctx context.Context, tld string) (release func()) {
+	limit := c.rateFor(tld).MaxConcurrent
+	if tld == "" || limit <= 0 {
+		return func() {}
+	}
+
+	c.tldMu.Lock()
+	if c.tldSems == nil {
+		c.tldSems = make(map[string]chan struct{})
+	}
+	sem, ok := c.tldSems[tld]
+	if !ok {
+		sem = make(chan struct{}, limit)
+		c.tldSems[tld] = sem
+	}
+	c.tldMu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+// waitTLD reserves the next start slot for tld and sleeps until it, the
+// same scheduling the WHOIS client uses per server.
+func (c *Checker) waitTLD(ctx context.Context, tld string) error {
+	delay := c.rateFor(tld).MinDelay
+	if tld == "" || delay <= 0 {
+		return nil
+	}
+
+	c.tldMu.Lock()
+	if c.tldNext == nil {
+		c.tldNext = make(map[string]time.Time)
+	}
+	now := c.opts.Clock.Now()
+	scheduled := now
+	if scheduled.Before(c.tldNext[tld]) {
+		scheduled = c.tldNext[tld]
+	}
+	c.tldNext[tld] = scheduled.Add(delay)
+	c.tldMu.Unlock()
+
+	return c.opts.Clock.Sleep(ctx, scheduled.Sub(now))
 }
 
 func (c *Checker) checkOne(ctx context.Context, input string) Result {
@@ -146,7 +416,7 @@ func (c *Checker) checkOne(ctx context.Context, input string) Result {
 		Confidence: "low",
 	}
 
-	ascii, err := domain.Normalize(input)
+	ascii, err := domain.NormalizeWithProfile(input, c.opts.IDNAProfile)
 	if err != nil {
 		r.Domain = strings.TrimSpace(input)
 		r.Error = err.Error()
@@ -161,85 +431,85 @@ func (c *Checker) checkOne(ctx context.Context, input string) Result {
 	if r.Input == ascii {
 		r.Input = ""
 	}
+	if domain.IsIDNLabel(r.TLD) {
+		// Homograph TLDs look like familiar ASCII ones once rendered; flag them.
+		r.Warnings = append(r.Warnings, fmt.Sprintf("tld %s is an IDN (%s), not an ASCII tld", r.TLD, domain.ToUnicode(r.TLD)))
+	}
 
-	if c.opts.RDAP != nil {
-		ev := c.opts.RDAP.LookupDomain(ctx, ascii)
-		r.Method = MethodRDAP
-		r.RDAPStatus = ev.Status
-		r.RDAPReason = ev.Reason
-		if ev.Err != nil {
-			r.RDAPError = ev.Err.Error()
-			if r.Error == "" {
-				r.Error = r.RDAPError
+	decidedBy := MethodNone
+methods:
+	for _, m := range c.methodsFor(r.TLD) {
+		var a answer
+		switch m {
+		case MethodRDAP:
+			if c.opts.RDAP == nil {
+				continue
 			}
+			if c.rdapAuthRequired(r.TLD) {
+				r.RDAPReason = "skipped: rdap requires auth"
+				continue
+			}
+			a = c.lookupRDAP(ctx, ascii, &r)
+		case MethodWHOIS:
+			if c.opts.NoWHOIS || c.opts.WHOIS == nil {
+				continue
+			}
+			a = c.lookupWHOIS(ctx, ascii, &r)
+		default:
+			continue
 		}
-		r.RDAPURL = ev.URL
-		r.RDAPCode = ev.HTTPStatus
-		if ev.Status == "available" {
-			r.Status = StatusAvailable
-			r.Registered = boolPtr(false)
-			r.Method = MethodRDAP
-			r.Confidence = ev.Confidence
-			r.Detail = ev.Reason
-			r.Error = ""
-			r.CheckedAt = time.Now().UTC().Format(time.RFC3339Nano)
-			r.DurationMs = time.Since(start).Milliseconds()
-			return r
-		}
-		if ev.Status == "taken" {
-			r.Status = StatusTaken
-			r.Registered = boolPtr(true)
-			r.Method = MethodRDAP
-			r.Confidence = ev.Confidence
-			r.Detail = ev.Reason
-			r.Error = ""
-			r.CheckedAt = time.Now().UTC().Format(time.RFC3339Nano)
-			r.DurationMs = time.Since(start).Milliseconds()
-			return r
-		}
-		if r.Detail == "" && ev.Reason != "" {
-			r.Detail = ev.Reason
-		}
-	}
 
-	if !c.opts.NoWHOIS && c.opts.WHOIS != nil {
-		ev := c.opts.WHOIS.LookupDomain(ctx, ascii)
-		r.Method = MethodWHOIS
-		r.WHOISStatus = ev.Status
-		r.WHOISReason = ev.Reason
-		if ev.Err != nil {
-			r.WHOISError = ev.Err.Error()
-			r.Error = r.WHOISError
+		if decidedBy != MethodNone {
+			// Cross-check: keep the first definitive answer unless this method
+			// definitively disagrees.
+			if a.definitive() && a.Status != string(r.Status) {
+				r.Conflict = fmt.Sprintf("%s says %s, %s says %s", decidedBy, r.Status, m, a.Status)
+				r.Status = StatusUnknown
+				r.Registered = nil
+				r.Confidence = "low"
+				r.Detail = "conflict: " + r.Conflict
+			}
+			break methods
 		}
-		r.WHOISServer = ev.Server
-		r.WHOISPattern = ev.Pattern
-		if ev.Status == "available" {
-			r.Status = StatusAvailable
-			r.Registered = boolPtr(false)
-			r.Method = MethodWHOIS
-			r.Confidence = ev.Confidence
-			r.Detail = ev.Reason
-			r.Error = ""
-			r.CheckedAt = time.Now().UTC().Format(time.RFC3339Nano)
-			r.DurationMs = time.Since(start).Milliseconds()
-			return r
+
+		r.Method = m
+		if a.Err != "" {
+			r.Error = a.Err
 		}
-		if ev.Status == "taken" {
-			r.Status = StatusTaken
-			r.Registered = boolPtr(true)
-			r.Method = MethodWHOIS
-			r.Confidence = ev.Confidence
-			r.Detail = ev.Reason
+		if a.definitive() {
+			switch a.Status {
+			case "available":
+				r.Status = StatusAvailable
+				r.Registered = boolPtr(false)
+			case "reserved":
+				r.Status = StatusReserved
+				r.Registered = nil
+			default:
+				r.Status = StatusTaken
+				r.Registered = boolPtr(true)
+			}
+			r.Confidence = a.Confidence
+			r.Detail = a.Reason
 			r.Error = ""
-			r.CheckedAt = time.Now().UTC().Format(time.RFC3339Nano)
-			r.DurationMs = time.Since(start).Milliseconds()
-			return r
+			if !c.opts.CrossCheck {
+				break methods
+			}
+			decidedBy = m
+			continue
 		}
-		if r.Detail == "" && ev.Reason != "" {
-			r.Detail = ev.Reason
+		if r.Detail == "" && a.Reason != "" {
+			r.Detail = a.Reason
 		}
 	}
 
+	if c.opts.DoubleCheck && r.Status == StatusAvailable {
+		c.doubleCheck(ctx, ascii, &r)
+	}
+
+	if c.opts.DNSDisambiguate && r.Status == StatusUnknown && r.Conflict == "" {
+		c.dnsDisambiguate(ctx, ascii, &r)
+	}
+
 	if r.Detail == "" {
 		// Summarize the per-method reasons for a single-line human summary.
 		switch {
@@ -259,6 +529,235 @@ func (c *Checker) checkOne(ctx context.Context, input string) Result {
 	return r
 }
 
+// doubleCheck asks the sources that haven't answered yet (another RDAP
+// mirror, RDAP itself after a WHOIS verdict, and WHOIS) whether an available
+// domain really is available.
+func (c *Checker) doubleCheck(ctx context.Context, ascii string, r *Result) {
+	var confirmed, unconfirmed []string
+	disagree := ""
+	note := func(source, status string) {
+		switch status {
+		case "available":
+			confirmed = append(confirmed, source)
+		case "taken", "reserved":
+			if disagree == "" {
+				disagree = source + " says " + status
+			}
+		default:
+			unconfirmed = append(unconfirmed, fmt.Sprintf("%s says %s", source, status))
+		}
+	}
+
+	if c.opts.RDAP != nil && !c.rdapAuthRequired(r.TLD) {
+		switch {
+		case r.Method == MethodRDAP:
+			if ev := c.opts.RDAP.LookupAlternate(ctx, ascii, r.RDAPURL); ev.URL != "" {
+				note("rdap mirror", ev.Status)
+			}
+		case r.RDAPStatus == "":
+			note("rdap", c.lookupRDAP(ctx, ascii, r).Status)
+		}
+	}
+	if !c.opts.NoWHOIS && c.opts.WHOIS != nil && r.Method != MethodWHOIS {
+		if r.WHOISStatus != "" {
+			// Already asked (cross-check); don't repeat the query.
+			note("whois", r.WHOISStatus)
+		} else {
+			note("whois", c.lookupWHOIS(ctx, ascii, r).Status)
+		}
+	}
+
+	if disagree == "" && len(confirmed) > 0 {
+		r.ConfirmedBy = confirmed
+		return
+	}
+
+	r.Conflict = fmt.Sprintf("%s says available, ", r.Method)
+	switch {
+	case disagree != "":
+		r.Conflict += disagree
+	case len(unconfirmed) > 0:
+		r.Conflict += strings.Join(unconfirmed, ", ")
+	default:
+		r.Conflict += "no second source to confirm"
+	}
+	r.Status = StatusUnknown
+	r.Registered = nil
+	r.Confidence = "low"
+	r.Detail = "double-check: " + r.Conflict
+}
+
+// dnsDisambiguate marks an unknown domain taken when it or its www host has
+// DNS records: something registered it. Conflicts are left alone, since a
+// source there already said available, and so are suffixes with wildcard DNS.
+// The lookup error stays on the result so it's clear why the verdict is weak.
+func (c *Checker) dnsDisambiguate(ctx context.Context, ascii string, r *Result) {
+	lookup := c.opts.LookupHost
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupHost
+	}
+	if c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
+	if c.hasWildcardDNS(ctx, lookup, ascii) {
+		return
+	}
+	for _, host := range []string{ascii, "www." + ascii} {
+		if addrs, err := lookup(ctx, host); err == nil && len(addrs) > 0 {
+			r.Status = StatusTaken
+			r.Registered = boolPtr(true)
+			r.Method = MethodDNS
+			r.Confidence = "low"
+			r.Detail = fmt.Sprintf("dns: %s resolves (rdap/whois inconclusive)", host)
+			return
+		}
+	}
+}
+
+// hasWildcardDNS reports whether a random label under ascii's registry
+// suffix resolves. The answer is cached per suffix for the run.
+func (c *Checker) hasWildcardDNS(ctx context.Context, lookup func(context.Context, string) ([]string, error), ascii string) bool {
+	suffixes := domain.SuffixCandidates(ascii)
+	if len(suffixes) == 0 {
+		return false
+	}
+	suffix := suffixes[0]
+	if v, ok := c.dnsWildcard.Load(suffix); ok {
+		return v.(bool)
+	}
+	probe := fmt.Sprintf("dothunt-probe-%016x.%s", rand.Uint64(), suffix)
+	addrs, err := lookup(ctx, probe)
+	wildcard := err == nil && len(addrs) > 0
+	if ctx.Err() == nil {
+		c.dnsWildcard.Store(suffix, wildcard)
+	}
+	return wildcard
+}
+
+// answer is one method's verdict in a method-independent shape.
+type answer struct {
+	Status     string
+	Confidence string
+	Reason     string
+	Err        string
+}
+
+func (a answer) definitive() bool {
+	return a.Status == "available" || a.Status == "taken" || a.Status == "reserved"
+}
+
+// methodsFor returns the lookup order for a TLD: its policy entry, else the
+// "*" entry, else RDAP then WHOIS.
+func (c *Checker) methodsFor(tld string) []Method {
+	if ms, ok := c.opts.MethodPolicy[tld]; ok {
+		return ms
+	}
+	if ms, ok := c.opts.MethodPolicy["*"]; ok {
+		return ms
+	}
+	return defaultMethods
+}
+
+var defaultMethods = []Method{MethodRDAP, MethodWHOIS}
+
+func (c *Checker) rdapAuthRequired(tld string) bool {
+	if c.opts.RDAPAuthRequired[tld] {
+		return true
+	}
+	// Only learn from 403s when WHOIS can answer instead; otherwise keep
+	// trying RDAP in case the 403 was transient.
+	if c.opts.NoWHOIS || c.opts.WHOIS == nil {
+		return false
+	}
+	_, forbidden := c.rdapForbidden.Load(tld)
+	return forbidden
+}
+
+func (c *Checker) lookupRDAP(ctx context.Context, ascii string, r *Result) answer {
+	ev := c.opts.RDAP.LookupDomain(ctx, ascii)
+	r.RDAPStatus = ev.Status
+	r.RDAPReason = ev.Reason
+	if ev.Err != nil {
+		r.RDAPError = ev.Err.Error()
+	}
+	r.RDAPURL = ev.URL
+	r.RDAPCode = ev.HTTPStatus
+	r.RDAPAttempts = ev.Attempts
+	if c.opts.Verbose {
+		r.RDAPBytes = ev.BytesRead
+		r.RDAPLatencyMs = ev.ServerLatencyMs
+	}
+	if ev.HTTPStatus == http.StatusForbidden {
+		// Registries that gate anonymous RDAP answer 403 for every domain, so
+		// don't spend a request (or a low-confidence detail) on the rest.
+		c.rdapForbidden.Store(r.TLD, true)
+	}
+	if len(ev.DomainStatus) > 0 {
+		r.DomainStatus = ev.DomainStatus
+	}
+	if len(ev.Variants) > 0 {
+		r.Variants = ev.Variants
+	}
+	if ev.Privacy != nil {
+		r.Privacy = ev.Privacy
+	}
+	return answer{Status: ev.Status, Confidence: ev.Confidence, Reason: ev.Reason, Err: r.RDAPError}
+}
+
+func (c *Checker) lookupWHOIS(ctx context.Context, ascii string, r *Result) answer {
+	ev := c.opts.WHOIS.LookupDomain(ctx, ascii)
+	if errors.Is(ev.Err, whois.ErrNoServer) && c.hasRDAPService(ctx, r.TLD) {
+		// RDAP-only registry: not having WHOIS is expected, not an error.
+		r.WHOISStatus = ev.Status
+		r.WHOISReason = "no whois; rdap only"
+		return answer{Status: ev.Status, Confidence: ev.Confidence, Reason: r.WHOISReason}
+	}
+	r.WHOISStatus = ev.Status
+	r.WHOISReason = ev.Reason
+	if ev.Err != nil {
+		r.WHOISError = ev.Err.Error()
+	}
+	r.WHOISServer = ev.Server
+	r.WHOISPattern = ev.Pattern
+	r.WHOISTruncated = ev.Truncated
+	if ev.Truncated {
+		r.Warnings = append(r.Warnings, "whois response was truncated; the verdict may have missed data")
+	}
+	r.WHOISAttempts = ev.Attempts
+	if c.opts.Verbose {
+		r.WHOISBytes = ev.BytesRead
+		r.WHOISLatencyMs = ev.ServerLatencyMs
+		r.WHOISServerChain = ev.ServerChain
+	}
+	if len(r.DomainStatus) == 0 && len(ev.DomainStatus) > 0 {
+		r.DomainStatus = ev.DomainStatus
+	}
+	return answer{Status: ev.Status, Confidence: ev.Confidence, Reason: ev.Reason, Err: r.WHOISError}
+}
+
+func (c *Checker) hasRDAPService(ctx context.Context, tld string) bool {
+	if c.opts.RDAP == nil {
+		return false
+	}
+	urls, err := c.opts.RDAP.ServiceURLs(ctx, tld)
+	return err == nil && len(urls) > 0
+}
+
+// IsDropping reports whether registry statuses show a registered domain on its
+// way out: in the redemption grace period or pending deletion. RDAP ("pending
+// delete") and EPP ("pendingDelete") spellings are both recognized.
+func IsDropping(statuses []string) bool {
+	for _, st := range statuses {
+		switch strings.ReplaceAll(strings.ToLower(st), " ", "") {
+		case "redemptionperiod", "pendingdelete":
+			return true
+		}
+	}
+	return false
+}
+
 func splitDomain(d string) (label, tld string) {
 	i := strings.LastIndexByte(d, '.')
 	if i < 0 || i == len(d)-1 {
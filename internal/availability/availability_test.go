@@ -0,0 +1,650 @@
+package availability
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+	"github.com/benithors/dothuntcli/internal/rdap"
+	"github.com/benithors/dothuntcli/internal/whois"
+)
+
+func TestCheckOne_IDNTLDWarning(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{NoWHOIS: true})
+
+	r := c.checkOne(context.Background(), "example.рф")
+	if r.TLD != "xn--p1ai" {
+		t.Fatalf("TLD=%q, want xn--p1ai", r.TLD)
+	}
+	if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "xn--p1ai") {
+		t.Fatalf("Warnings=%q, want IDN tld warning", r.Warnings)
+	}
+
+	r = c.checkOne(context.Background(), "example.com")
+	if len(r.Warnings) != 0 {
+		t.Fatalf("Warnings=%q, want none for ASCII tld", r.Warnings)
+	}
+}
+
+func TestCheckOne_WarningsAccumulate(t *testing.T) {
+	t.Parallel()
+
+	// An IDN TLD and a WHOIS response past the 1 MiB cap.
+	c := NewChecker(Options{
+		WHOIS:        newTestWHOIS(t, "Domain Name: example.xn--p1ai\n"+strings.Repeat("%\n", 1<<20)),
+		MethodPolicy: map[string][]Method{"*": {MethodWHOIS}},
+	})
+
+	r := c.checkOne(context.Background(), "example.рф")
+	if len(r.Warnings) != 2 || !strings.Contains(r.Warnings[0], "IDN") || !strings.Contains(r.Warnings[1], "truncated") {
+		t.Fatalf("Warnings=%q, want IDN and truncation warnings", r.Warnings)
+	}
+	if r.Status != StatusTaken {
+		t.Fatalf("Status=%q, want taken", r.Status)
+	}
+}
+
+func BenchmarkCheckDomains_RDAP(b *testing.B) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := NewChecker(Options{
+		RDAP: rdap.NewClient(rdap.Options{
+			BootstrapURL: srv.URL + "/dns.json",
+			CacheDir:     b.TempDir(),
+			HTTPClient:   srv.Client(),
+		}),
+		NoWHOIS: true,
+	})
+
+	inputs := make([]string, 64)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("bench-%d.com", i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.CheckDomains(context.Background(), inputs)
+	}
+}
+
+func TestCheckOne_CrossCheckConflict(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP:       newTestRDAP(t, map[string]int{"example.com": http.StatusNotFound}),
+		WHOIS:      newTestWHOIS(t, "Domain Name: example.com\nRegistrar: Example Registrar\n"),
+		CrossCheck: true,
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusUnknown {
+		t.Fatalf("Status=%q, want unknown", r.Status)
+	}
+	if r.RDAPStatus != "available" || r.WHOISStatus != "taken" {
+		t.Fatalf("rdap=%q whois=%q, want available/taken", r.RDAPStatus, r.WHOISStatus)
+	}
+	if !strings.Contains(r.Conflict, "whois says taken") {
+		t.Fatalf("Conflict=%q, want whois disagreement", r.Conflict)
+	}
+}
+
+func TestCheckOne_CrossCheckAgreement(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP:       newTestRDAP(t, map[string]int{"example.com": http.StatusOK}),
+		WHOIS:      newTestWHOIS(t, "Domain Name: example.com\nRegistrar: Example Registrar\n"),
+		CrossCheck: true,
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusTaken || r.Method != MethodRDAP {
+		t.Fatalf("Status=%q Method=%q, want taken via rdap", r.Status, r.Method)
+	}
+	if r.Conflict != "" {
+		t.Fatalf("Conflict=%q, want empty", r.Conflict)
+	}
+}
+
+// newTestRDAP serves a bootstrap for "com" and answers domain lookups with the
+// given HTTP status codes (404 for anything unlisted).
+func newTestRDAP(t *testing.T, codes map[string]int) *rdap.Client {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		code, ok := codes[strings.TrimPrefix(r.URL.Path, "/rdap/domain/")]
+		if !ok {
+			code = http.StatusNotFound
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+
+	return rdap.NewClient(rdap.Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+	})
+}
+
+// newTestWHOIS returns a WHOIS client whose IANA referral points at a fake
+// registry server that answers every query with body.
+func newTestWHOIS(t *testing.T, body string) *whois.Client {
+	t.Helper()
+
+	return whois.NewClient(whois.Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go func() {
+				defer server.Close()
+				if _, err := bufio.NewReader(server).ReadString('\n'); err != nil {
+					return
+				}
+				if strings.HasPrefix(addr, "whois.iana.org:") {
+					_, _ = io.WriteString(server, "whois: whois.registry.test\n")
+					return
+				}
+				_, _ = io.WriteString(server, body)
+			}()
+			return client, nil
+		},
+	})
+}
+
+func TestCheckOne_MethodPolicy(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP:         newTestRDAP(t, map[string]int{"example.com": http.StatusNotFound}),
+		WHOIS:        newTestWHOIS(t, "Domain Name: example.com\nRegistrar: Example Registrar\n"),
+		MethodPolicy: map[string][]Method{"com": {MethodWHOIS}},
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusTaken || r.Method != MethodWHOIS {
+		t.Fatalf("Status=%q Method=%q, want taken via whois", r.Status, r.Method)
+	}
+	if r.RDAPStatus != "" {
+		t.Fatalf("RDAPStatus=%q, want RDAP skipped by policy", r.RDAPStatus)
+	}
+}
+
+func TestCheckOne_RDAPOnlyTLDSkipsWHOISGracefully(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP: newTestRDAP(t, map[string]int{"example.com": http.StatusInternalServerError}),
+		WHOIS: whois.NewClient(whois.Options{
+			CacheDir:          t.TempDir(),
+			MinDelayPerServer: time.Nanosecond,
+			DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				client, server := net.Pipe()
+				go func() {
+					defer server.Close()
+					_, _ = bufio.NewReader(server).ReadString('\n')
+					_, _ = io.WriteString(server, "domain: COM\nstatus: ACTIVE\n")
+				}()
+				return client, nil
+			},
+		}),
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.WHOISReason != "no whois; rdap only" {
+		t.Fatalf("WHOISReason=%q, want rdap-only reason", r.WHOISReason)
+	}
+	if r.WHOISError != "" {
+		t.Fatalf("WHOISError=%q, want empty", r.WHOISError)
+	}
+	if r.Error != r.RDAPError {
+		t.Fatalf("Error=%q, want only the RDAP error %q", r.Error, r.RDAPError)
+	}
+}
+
+func TestIsDropping(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		statuses []string
+		want     bool
+	}{
+		{[]string{"client transfer prohibited", "pending delete"}, true},
+		{[]string{"redemptionPeriod"}, true},
+		{[]string{"active"}, false},
+		{nil, false},
+	} {
+		if got := IsDropping(tc.statuses); got != tc.want {
+			t.Fatalf("IsDropping(%v)=%v, want %v", tc.statuses, got, tc.want)
+		}
+	}
+}
+
+func TestCheckDomains_ShuffleKeepsInputOrder(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{Shuffle: true, Seed: 42, Concurrency: 1})
+
+	order := c.dispatchOrder(20)
+	if again := c.dispatchOrder(20); fmt.Sprint(order) != fmt.Sprint(again) {
+		t.Fatalf("dispatchOrder not deterministic for a fixed seed: %v vs %v", order, again)
+	}
+	sorted := true
+	seen := map[int]bool{}
+	for i, idx := range order {
+		seen[idx] = true
+		if idx != i {
+			sorted = false
+		}
+	}
+	if len(seen) != 20 || sorted {
+		t.Fatalf("dispatchOrder=%v, want a shuffled permutation of 0..19", order)
+	}
+
+	// Invalid inputs fail fast without network access.
+	inputs := []string{"a..x", "b..x", "c..x", "d..x", "e..x"}
+	got := c.CheckDomains(context.Background(), inputs)
+	for i, r := range got {
+		if r.Input != inputs[i] {
+			t.Fatalf("result[%d].Input=%q, want %q", i, r.Input, inputs[i])
+		}
+	}
+}
+
+func TestCheckDomains_RDAPForbiddenFallsBackToWHOIS(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP: newTestRDAP(t, map[string]int{
+			"one.com": http.StatusForbidden,
+			"two.com": http.StatusForbidden,
+		}),
+		WHOIS:       newTestWHOIS(t, "No match for domain.\n"),
+		Concurrency: 1,
+	})
+
+	got := c.CheckDomains(context.Background(), []string{"one.com", "two.com"})
+	for _, r := range got {
+		if r.Status != StatusAvailable || r.Method != MethodWHOIS || r.Confidence != "medium" {
+			t.Fatalf("%s: status=%q method=%q confidence=%q, want available via whois at medium", r.Domain, r.Status, r.Method, r.Confidence)
+		}
+	}
+	if got[0].RDAPCode != http.StatusForbidden {
+		t.Fatalf("first rdap code=%d, want 403", got[0].RDAPCode)
+	}
+	if got[1].RDAPCode != 0 || !strings.Contains(got[1].RDAPReason, "skipped") {
+		t.Fatalf("second rdap code=%d reason=%q, want RDAP skipped after 403", got[1].RDAPCode, got[1].RDAPReason)
+	}
+}
+
+func TestCheckOne_RDAPAuthRequiredSkipsRDAP(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP:             newTestRDAP(t, map[string]int{"example.com": http.StatusOK}),
+		WHOIS:            newTestWHOIS(t, "No match for domain.\n"),
+		RDAPAuthRequired: map[string]bool{"com": true},
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Method != MethodWHOIS || r.RDAPCode != 0 {
+		t.Fatalf("method=%q rdap code=%d, want whois only", r.Method, r.RDAPCode)
+	}
+}
+
+func TestCheckOne_DoubleCheck(t *testing.T) {
+	t.Parallel()
+
+	agree := NewChecker(Options{
+		RDAP:        newTestRDAP(t, nil),
+		WHOIS:       newTestWHOIS(t, "No match for domain.\n"),
+		DoubleCheck: true,
+	})
+	r := agree.checkOne(context.Background(), "example.com")
+	if r.Status != StatusAvailable || fmt.Sprint(r.ConfirmedBy) != "[whois]" {
+		t.Fatalf("Status=%q ConfirmedBy=%v, want available confirmed by whois", r.Status, r.ConfirmedBy)
+	}
+
+	disagree := NewChecker(Options{
+		RDAP:        newTestRDAP(t, nil),
+		WHOIS:       newTestWHOIS(t, "Domain Name: example.com\nRegistrar: Example Registrar\n"),
+		DoubleCheck: true,
+	})
+	r = disagree.checkOne(context.Background(), "example.com")
+	if r.Status != StatusUnknown || r.Conflict != "rdap says available, whois says taken" {
+		t.Fatalf("Status=%q Conflict=%q, want unknown with whois disagreement", r.Status, r.Conflict)
+	}
+
+	alone := NewChecker(Options{
+		RDAP:        newTestRDAP(t, nil),
+		NoWHOIS:     true,
+		DoubleCheck: true,
+	})
+	r = alone.checkOne(context.Background(), "example.com")
+	if r.Status != StatusUnknown || !strings.Contains(r.Conflict, "no second source") {
+		t.Fatalf("Status=%q Conflict=%q, want unknown without a second source", r.Status, r.Conflict)
+	}
+}
+
+func TestCheckOne_DNSDisambiguate(t *testing.T) {
+	t.Parallel()
+
+	var looked []string
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		looked = append(looked, host)
+		if host == "www.example.com" {
+			return []string{"192.0.2.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	c := NewChecker(Options{
+		RDAP:            newTestRDAP(t, map[string]int{"example.com": http.StatusForbidden}),
+		NoWHOIS:         true,
+		DNSDisambiguate: true,
+		LookupHost:      lookup,
+	})
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusTaken || r.Method != MethodDNS || r.Confidence != "low" {
+		t.Fatalf("Status=%q Method=%q Confidence=%q, want low-confidence taken via dns", r.Status, r.Method, r.Confidence)
+	}
+	if !strings.Contains(r.Detail, "www.example.com resolves") {
+		t.Fatalf("Detail=%q, want note about www", r.Detail)
+	}
+	if r.Error == "" {
+		t.Fatalf("Error is empty, want the inconclusive lookup's error kept")
+	}
+
+	// Under wildcard DNS everything resolves, so nothing is concluded.
+	wildcard := func(_ context.Context, host string) ([]string, error) {
+		return []string{"192.0.2.1"}, nil
+	}
+	c = NewChecker(Options{
+		RDAP:            newTestRDAP(t, map[string]int{"example.com": http.StatusForbidden}),
+		NoWHOIS:         true,
+		DNSDisambiguate: true,
+		LookupHost:      wildcard,
+	})
+	r = c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusUnknown || r.Method == MethodDNS {
+		t.Fatalf("Status=%q Method=%q, want unknown under wildcard DNS", r.Status, r.Method)
+	}
+
+	// A definitive answer is never second-guessed.
+	looked = nil
+	c = NewChecker(Options{
+		RDAP:            newTestRDAP(t, nil),
+		NoWHOIS:         true,
+		DNSDisambiguate: true,
+		LookupHost:      lookup,
+	})
+	r = c.checkOne(context.Background(), "example.com")
+	if r.Status != StatusAvailable || len(looked) != 0 {
+		t.Fatalf("Status=%q looked=%v, want available without DNS lookups", r.Status, looked)
+	}
+}
+
+func TestCheckDomains_TLDDelaySpacesSameTLD(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewChecker(Options{Concurrency: 1, TLDDelay: 40 * time.Millisecond, Clock: clk})
+	c.CheckDomains(context.Background(), []string{"a.com", "b.com", "a.net", "c.com"})
+	if want := "[40ms 40ms]"; fmt.Sprint(clk.Sleeps()) != want {
+		t.Fatalf("sleeps=%v, want %s (only .com spaced)", clk.Sleeps(), want)
+	}
+}
+
+func TestCheckDomains_TLDRates(t *testing.T) {
+	t.Parallel()
+
+	// Per-TLD delays replace TLDDelay; "*" covers the rest.
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewChecker(Options{
+		Concurrency: 1,
+		TLDDelay:    time.Second,
+		TLDRates:    map[string]TLDRate{"com": {MinDelay: 40 * time.Millisecond}, "*": {}},
+		Clock:       clk,
+	})
+	c.CheckDomains(context.Background(), []string{"a.com", "a.net", "b.net", "b.com"})
+	if want := "[40ms]"; fmt.Sprint(clk.Sleeps()) != want {
+		t.Fatalf("sleeps=%v, want %s", clk.Sleeps(), want)
+	}
+
+	// MaxConcurrent caps lookups in flight on one TLD.
+	var mu sync.Mutex
+	var inFlight, peak int
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c = NewChecker(Options{
+		RDAP: rdap.NewClient(rdap.Options{
+			BootstrapURL: srv.URL + "/dns.json",
+			CacheDir:     t.TempDir(),
+			HTTPClient:   srv.Client(),
+		}),
+		NoWHOIS:     true,
+		Concurrency: 8,
+		TLDRates:    map[string]TLDRate{"com": {MaxConcurrent: 2}},
+	})
+	var inputs []string
+	for i := range 8 {
+		inputs = append(inputs, fmt.Sprintf("d%d.com", i))
+	}
+	c.CheckDomains(context.Background(), inputs)
+	if peak != 2 {
+		t.Fatalf("peak in-flight .com lookups=%d, want 2", peak)
+	}
+}
+
+func TestCheckOne_WHOISReserved(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{
+		RDAP:         newTestRDAP(t, nil),
+		WHOIS:        newTestWHOIS(t, "Reserved by the Registry\n"),
+		MethodPolicy: map[string][]Method{"*": {MethodWHOIS}},
+	})
+
+	r := c.checkOne(context.Background(), "nic.com")
+	if r.Status != StatusReserved || r.Registered != nil {
+		t.Fatalf("Status=%q Registered=%v, want reserved with unknown registration", r.Status, r.Registered)
+	}
+}
+
+// recordingLimiter notes the key of every request it paces.
+type recordingLimiter struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (l *recordingLimiter) Wait(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys = append(l.keys, key)
+	return nil
+}
+
+func TestCheckOne_RegistryLimiterSharedByMethods(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	lim := &recordingLimiter{}
+	c := NewChecker(Options{
+		RDAP: rdap.NewClient(rdap.Options{
+			BootstrapURL: srv.URL + "/dns.json",
+			CacheDir:     t.TempDir(),
+			HTTPClient:   srv.Client(),
+			Limiter:      lim,
+		}),
+		WHOIS: whois.NewClient(whois.Options{
+			CacheDir:          t.TempDir(),
+			MinDelayPerServer: time.Nanosecond,
+			Limiter:           lim,
+			DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				client, server := net.Pipe()
+				go func() {
+					defer server.Close()
+					if _, err := bufio.NewReader(server).ReadString('\n'); err != nil {
+						return
+					}
+					if strings.HasPrefix(addr, "whois.iana.org:") {
+						_, _ = io.WriteString(server, "whois: whois.registry.test\n")
+						return
+					}
+					_, _ = io.WriteString(server, "No match for \"EXAMPLE.COM\".\n")
+				}()
+				return client, nil
+			},
+		}),
+	})
+
+	r := c.checkOne(context.Background(), "example.com")
+	if r.Method != MethodWHOIS || r.Status != StatusAvailable {
+		t.Fatalf("Method=%q Status=%q, want whois fallback to available", r.Method, r.Status)
+	}
+	// One RDAP request and one WHOIS query; the IANA referral isn't paced.
+	if got := strings.Join(lim.keys, ","); got != "com,com" {
+		t.Fatalf("limiter keys=%q, want com,com", got)
+	}
+}
+
+func TestRegistryLimiter_PerKey(t *testing.T) {
+	t.Parallel()
+
+	l := NewRegistryLimiter(20)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		_ = l.Wait(ctx, "com")
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("same-key elapsed=%v, want >= 80ms", elapsed)
+	}
+
+	start = time.Now()
+	_ = l.Wait(ctx, "net")
+	_ = l.Wait(ctx, "org")
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Fatalf("new-key elapsed=%v, want no wait", elapsed)
+	}
+
+	if NewRegistryLimiter(0) != nil {
+		t.Fatal("NewRegistryLimiter(0) != nil, want unlimited")
+	}
+}
+
+func TestCheckDomainsFunc_CallsBackPerResult(t *testing.T) {
+	t.Parallel()
+
+	c := NewChecker(Options{Concurrency: 2, NoWHOIS: true})
+
+	seen := make(map[string]bool)
+	results, _ := c.CheckDomainsFunc(context.Background(), []string{"a.com", "b.net", "bad..name"}, func(r Result) {
+		seen[r.Domain] = true
+	})
+	if len(seen) != len(results) {
+		t.Fatalf("callbacks=%d, want one per result (%d)", len(seen), len(results))
+	}
+	for _, r := range results {
+		if !seen[r.Domain] {
+			t.Fatalf("no callback for %q", r.Domain)
+		}
+	}
+}
+
+func TestCheckDomainsFunc_MaxUnknownRatio(t *testing.T) {
+	t.Parallel()
+
+	inputs := make([]string, 50)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("d%02d.com", i)
+	}
+
+	// No lookup methods, so every result is unknown.
+	c := NewChecker(Options{Concurrency: 1, NoWHOIS: true, MaxUnknownRatio: 0.5, UnknownSample: 5})
+	results, err := c.CheckDomainsFunc(context.Background(), inputs, nil)
+	if !errors.Is(err, ErrTooManyUnknown) {
+		t.Fatalf("err=%v, want ErrTooManyUnknown", err)
+	}
+	if len(results) != 5 || results[0].Domain != "d00.com" || results[4].Domain != "d04.com" {
+		t.Fatalf("got %d results, want the first 5 in input order", len(results))
+	}
+
+	c = NewChecker(Options{Concurrency: 4, NoWHOIS: true})
+	if results, err := c.CheckDomainsFunc(context.Background(), inputs, nil); err != nil || len(results) != len(inputs) {
+		t.Fatalf("without breaker: %d results, err=%v, want all", len(results), err)
+	}
+}
+
+func TestCheckOne_VerboseServerMetrics(t *testing.T) {
+	t.Parallel()
+
+	const body = "Domain Name: example.com\nRegistrar: Example Registrar\n"
+	for _, verbose := range []bool{false, true} {
+		c := NewChecker(Options{
+			WHOIS:        newTestWHOIS(t, body),
+			MethodPolicy: map[string][]Method{"*": {MethodWHOIS}},
+			Verbose:      verbose,
+		})
+		r := c.checkOne(context.Background(), "example.com")
+		want := 0
+		if verbose {
+			want = len(body)
+		}
+		if r.WHOISBytes != want {
+			t.Fatalf("verbose=%v: WHOISBytes=%d, want %d", verbose, r.WHOISBytes, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package availability
+
+import (
+	"context"
+	"sync"
+
+	"github.com/benithors/dothuntcli/internal/ratelimit"
+)
+
+// RegistryLimiter caps the request rate per registry across both lookup
+// methods. It is handed to the RDAP and WHOIS clients as their Limiter, so a
+// WHOIS fallback waits its turn behind the RDAP request that preceded it on
+// the same registry. Keys are TLDs, the one registry identity both clients
+// know. A nil *RegistryLimiter never waits.
+type RegistryLimiter struct {
+	perSecond float64
+
+	mu      sync.Mutex
+	buckets map[string]*ratelimit.Bucket
+}
+
+// NewRegistryLimiter allows perSecond requests per second to each registry.
+// It returns nil (unlimited) when perSecond <= 0.
+func NewRegistryLimiter(perSecond float64) *RegistryLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &RegistryLimiter{perSecond: perSecond, buckets: make(map[string]*ratelimit.Bucket)}
+}
+
+// Wait blocks until a request to key's registry may be sent or ctx is done.
+func (l *RegistryLimiter) Wait(ctx context.Context, key string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = ratelimit.NewBucket(l.perSecond, 1)
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+	return b.Wait(ctx)
+}
@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFake_SleepAdvances(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	ctx := context.Background()
+
+	_ = f.Sleep(ctx, 100*time.Millisecond)
+	_ = f.Sleep(ctx, 0)
+	f.Advance(time.Second)
+	_ = f.Sleep(ctx, 50*time.Millisecond)
+
+	if got := f.Now().Sub(start); got != 1150*time.Millisecond {
+		t.Fatalf("elapsed=%v, want 1.15s", got)
+	}
+	if want := "[100ms 50ms]"; fmt.Sprint(f.Sleeps()) != want {
+		t.Fatalf("sleeps=%v, want %s", f.Sleeps(), want)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := f.Sleep(canceled, time.Second); err == nil {
+		t.Fatalf("Sleep on canceled ctx: err=nil, want context.Canceled")
+	}
+}
@@ -0,0 +1,246 @@
+// Package demo runs in-process RDAP and WHOIS servers with canned answers,
+// plus a stub registrar, so the whole check pipeline can be tried (or
+// tested end to end) without touching the internet.
+package demo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/registrar"
+)
+
+// WHOISServer is the name the mock IANA refers every demo TLD to. Dial sends
+// it (and every other WHOIS host) to the local listener.
+const WHOISServer = "whois.demo.test"
+
+// Fixtures describes what the mock registries know.
+type Fixtures struct {
+	// RDAPTLDs are listed in the mock RDAP bootstrap. WHOISTLDs only have a
+	// WHOIS server, like registries that never adopted RDAP.
+	RDAPTLDs  []string
+	WHOISTLDs []string
+
+	// Taken domains (lower-case ASCII) are registered; every other name in
+	// a demo TLD is available.
+	Taken []string
+
+	// Prices maps a TLD to the stub registrar's yearly price in USD.
+	Prices map[string]string
+}
+
+// Default is the data behind --demo.
+var Default = Fixtures{
+	RDAPTLDs:  []string{"com", "net", "org", "dev", "io"},
+	WHOISTLDs: []string{"de", "ch"},
+	Taken: []string{
+		"example.com", "example.net", "example.org", "example.de", "example.ch",
+		"google.com", "openai.com", "github.io", "go.dev",
+	},
+	Prices: map[string]string{
+		"com": "10.99",
+		"net": "12.99",
+		"org": "11.49",
+		"dev": "14.99",
+		"io":  "39.99",
+		"de":  "6.99",
+		"ch":  "9.99",
+	},
+}
+
+// premiumPrice is what the stub registrar asks for labels of three
+// characters or fewer.
+const premiumPrice = "2500.00"
+
+// Servers is a running set of mock registries. Close it when done.
+type Servers struct {
+	// BootstrapURL is the mock RDAP bootstrap (IANA dns.json format).
+	BootstrapURL string
+	// WHOISAddr is the host:port of the mock WHOIS server, which answers
+	// both IANA referral and domain queries.
+	WHOISAddr string
+
+	fx    Fixtures
+	taken map[string]bool
+	rdap  *httptest.Server
+	ln    net.Listener
+	wg    sync.WaitGroup
+}
+
+// Start launches the mock RDAP and WHOIS servers on loopback ports.
+func Start(fx Fixtures) (*Servers, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("start mock whois server: %w", err)
+	}
+	s := &Servers{
+		WHOISAddr: ln.Addr().String(),
+		fx:        fx,
+		taken:     make(map[string]bool, len(fx.Taken)),
+		ln:        ln,
+	}
+	for _, d := range fx.Taken {
+		s.taken[strings.ToLower(d)] = true
+	}
+
+	s.rdap = httptest.NewServer(http.HandlerFunc(s.serveRDAP))
+	s.BootstrapURL = s.rdap.URL + "/dns.json"
+
+	s.wg.Add(1)
+	go s.acceptWHOIS()
+	return s, nil
+}
+
+// Close stops both servers and waits for open WHOIS connections to finish.
+func (s *Servers) Close() {
+	s.rdap.Close()
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+// Dial connects to the mock WHOIS server whatever addr says, for use as
+// whois.Options.DialFunc.
+func (s *Servers) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, network, s.WHOISAddr)
+}
+
+// LookupHost resolves nothing, keeping DNS checks offline as well.
+func (s *Servers) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (s *Servers) serveRDAP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/dns.json":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"version":     "1.0",
+			"publication": time.Now().UTC().Format(time.RFC3339),
+			"services":    [][][]string{{s.fx.RDAPTLDs, {s.rdap.URL + "/rdap/"}}},
+		})
+	case strings.HasPrefix(r.URL.Path, "/rdap/domain/"):
+		name := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/rdap/domain/"))
+		w.Header().Set("Content-Type", "application/rdap+json")
+		if !s.taken[name] {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"errorCode":404,"title":"Not Found","description":["%s is not registered"]}`, name)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"objectClassName": "domain",
+			"ldhName":         name,
+			"status":          []string{"client transfer prohibited"},
+			"events": []map[string]string{
+				{"eventAction": "registration", "eventDate": "2001-01-01T00:00:00Z"},
+				{"eventAction": "expiration", "eventDate": "2031-01-01T00:00:00Z"},
+			},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Servers) acceptWHOIS() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return
+			}
+			_, _ = io.WriteString(conn, s.whoisResponse(strings.ToLower(strings.TrimSpace(line))))
+		}()
+	}
+}
+
+// whoisResponse answers q the way IANA (bare TLDs) or a registry (domains)
+// would.
+func (s *Servers) whoisResponse(q string) string {
+	if !strings.Contains(q, ".") {
+		if s.knowsTLD(q) {
+			return fmt.Sprintf("domain:       %s\nrefer:        %s\nwhois:        %s\n", strings.ToUpper(q), WHOISServer, WHOISServer)
+		}
+		return fmt.Sprintf("%% This query returned 0 objects (%s is not a demo TLD).\n", q)
+	}
+	if !s.taken[q] {
+		return fmt.Sprintf("No match for %q.\n", strings.ToUpper(q))
+	}
+	return fmt.Sprintf("Domain Name: %s\nRegistrar: Demo Registrar\nCreation Date: 2001-01-01T00:00:00Z\nDomain Status: clientTransferProhibited\n", strings.ToUpper(q))
+}
+
+func (s *Servers) knowsTLD(tld string) bool {
+	return slices.Contains(s.fx.RDAPTLDs, tld) || slices.Contains(s.fx.WHOISTLDs, tld)
+}
+
+// Registrar is a stub registrar.Client and PriceLister over the same
+// fixtures: every untaken name in a priced TLD is buyable, and short labels
+// are premium.
+type Registrar struct {
+	fx    Fixtures
+	taken map[string]bool
+}
+
+// NewRegistrar returns a stub registrar for fx.
+func NewRegistrar(fx Fixtures) *Registrar {
+	taken := make(map[string]bool, len(fx.Taken))
+	for _, d := range fx.Taken {
+		taken[strings.ToLower(d)] = true
+	}
+	return &Registrar{fx: fx, taken: taken}
+}
+
+func (r *Registrar) Name() string { return "demo" }
+
+func (r *Registrar) CheckDomain(ctx context.Context, domain string) (registrar.DomainCheck, error) {
+	if err := ctx.Err(); err != nil {
+		return registrar.DomainCheck{}, err
+	}
+	domain = strings.ToLower(domain)
+	label, tld, ok := strings.Cut(domain, ".")
+	price, priced := r.fx.Prices[tld]
+	if !ok || !priced || r.taken[domain] {
+		return registrar.DomainCheck{}, nil
+	}
+	check := registrar.DomainCheck{
+		Buyable:      true,
+		Price:        price,
+		RegularPrice: price,
+		RenewalPrice: price,
+		Currency:     "USD",
+		MinDuration:  1,
+	}
+	if len(label) <= 3 {
+		check.Premium = true
+		check.Price = premiumPrice
+		check.RegularPrice = premiumPrice
+	}
+	return check, nil
+}
+
+func (r *Registrar) ListTLDPricing(ctx context.Context) (map[string]registrar.Price, error) {
+	out := make(map[string]registrar.Price, len(r.fx.Prices))
+	for tld, p := range r.fx.Prices {
+		out[tld] = registrar.Price{Registration: p, Renewal: p, Transfer: p}
+	}
+	return out, nil
+}
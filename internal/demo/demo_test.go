@@ -0,0 +1,62 @@
+package demo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/rdap"
+	"github.com/benithors/dothuntcli/internal/whois"
+)
+
+func TestServers_RDAPAndWHOIS(t *testing.T) {
+	t.Parallel()
+
+	s, err := Start(Default)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(s.Close)
+	ctx := context.Background()
+
+	rc := rdap.NewClient(rdap.Options{BootstrapURL: s.BootstrapURL, NoDiskCache: true})
+	if ev := rc.LookupDomain(ctx, "example.com"); ev.Status != "taken" {
+		t.Fatalf("rdap example.com status=%q (%v), want taken", ev.Status, ev.Err)
+	}
+	if ev := rc.LookupDomain(ctx, "free-name.dev"); ev.Status != "available" {
+		t.Fatalf("rdap free-name.dev status=%q (%v), want available", ev.Status, ev.Err)
+	}
+
+	wc := whois.NewClient(whois.Options{DialFunc: s.Dial, NoDiskCache: true})
+	if got, err := wc.ServerForTLD(ctx, "de"); err != nil || got != WHOISServer {
+		t.Fatalf("ServerForTLD(de)=%q, %v; want %s", got, err, WHOISServer)
+	}
+	if ev := wc.LookupDomain(ctx, "example.de"); ev.Status != "taken" {
+		t.Fatalf("whois example.de status=%q (%v), want taken", ev.Status, ev.Err)
+	}
+	if ev := wc.LookupDomain(ctx, "free-name.de"); ev.Status != "available" {
+		t.Fatalf("whois free-name.de status=%q (%v), want available", ev.Status, ev.Err)
+	}
+	if _, err := wc.ServerForTLD(ctx, "xyz"); err == nil {
+		t.Fatalf("ServerForTLD(xyz): err=nil, want no server outside the demo TLDs")
+	}
+}
+
+func TestRegistrar_CheckDomain(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistrar(Default)
+	ctx := context.Background()
+
+	if c, err := r.CheckDomain(ctx, "example.com"); err != nil || c.Buyable {
+		t.Fatalf("example.com=%+v, %v; want not buyable", c, err)
+	}
+	if c, err := r.CheckDomain(ctx, "longername.io"); err != nil || !c.Buyable || c.Premium || c.Price != "39.99" {
+		t.Fatalf("longername.io=%+v, %v; want buyable at 39.99", c, err)
+	}
+	if c, err := r.CheckDomain(ctx, "ab.com"); err != nil || !c.Premium || c.Price != premiumPrice || c.RenewalPrice != "10.99" {
+		t.Fatalf("ab.com=%+v, %v; want premium", c, err)
+	}
+	if c, err := r.CheckDomain(ctx, "name.xyz"); err != nil || c.Buyable {
+		t.Fatalf("name.xyz=%+v, %v; want not buyable outside priced TLDs", c, err)
+	}
+}
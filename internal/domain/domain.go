@@ -2,14 +2,18 @@ package domain
 
 import (
 	"bufio"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
 )
 
 // Normalize attempts to turn user input into an ASCII domain name suitable for
@@ -17,8 +21,18 @@ import (
 //
 // It is intentionally permissive for agent/human inputs (allows URLs, strips
 // paths, strips port). It returns an error if the remaining value is not a
-// valid domain name.
+// valid domain name. IDNA conversion uses ProfileLookup.
 func Normalize(input string) (string, error) {
+	return NormalizeWithProfile(input, ProfileLookup)
+}
+
+// NormalizeWithProfile is Normalize with the IDNA conversion done under
+// profile p (the zero value means ProfileLookup).
+func NormalizeWithProfile(input string, p Profile) (string, error) {
+	if IsNormalized(input) {
+		return input, nil
+	}
+
 	s := strings.TrimSpace(input)
 	if s == "" {
 		return "", fmt.Errorf("empty domain")
@@ -57,7 +71,7 @@ func Normalize(input string) (string, error) {
 		return "", fmt.Errorf("empty domain")
 	}
 
-	ascii, err := idna.Lookup.ToASCII(s)
+	ascii, err := p.idna().ToASCII(s)
 	if err != nil {
 		return "", fmt.Errorf("idna: %w", err)
 	}
@@ -74,6 +88,179 @@ func Normalize(input string) (string, error) {
 	return ascii, nil
 }
 
+// Profile selects how Unicode input is converted to ASCII labels.
+//
+//   - ProfileLookup (default) maps input the way browsers do: compatibility
+//     forms fold ("ⅷ" becomes "viii", full-width letters become ASCII) and
+//     invalid labels, such as ones with hyphens in positions 3-4 or
+//     misplaced joiners, are rejected.
+//   - ProfileRegistration applies the same validation without mapping, so
+//     anything that would need folding is an error. Use it to check a name
+//     exactly as a registrar would accept it.
+//   - ProfilePunycode only encodes: no mapping and no validation beyond
+//     what Normalize itself checks afterwards. It accepts names some
+//     registries allow but IDNA2008 rejects, and also names no browser can
+//     reach; results for them are only as good as the registry's answer.
+type Profile string
+
+const (
+	ProfileLookup       Profile = "lookup"
+	ProfileRegistration Profile = "registration"
+	ProfilePunycode     Profile = "punycode"
+)
+
+// ParseProfile validates a user-supplied profile name.
+func ParseProfile(s string) (Profile, error) {
+	switch p := Profile(strings.ToLower(strings.TrimSpace(s))); p {
+	case ProfileLookup, ProfileRegistration, ProfilePunycode:
+		return p, nil
+	case "":
+		return ProfileLookup, nil
+	default:
+		return "", fmt.Errorf("invalid idna profile %q (use lookup|registration|punycode)", s)
+	}
+}
+
+func (p Profile) idna() *idna.Profile {
+	switch p {
+	case ProfileRegistration:
+		return idna.Registration
+	case ProfilePunycode:
+		return idna.Punycode
+	default:
+		return idna.Lookup
+	}
+}
+
+// IsNormalized reports whether s is already in Normalize's output form, so the
+// IDNA round-trip can be skipped: lower-case LDH labels, at least one dot, and
+// no label with hyphens in positions 3-4 (punycode "xn--" and reserved forms
+// still need full IDNA validation).
+func IsNormalized(s string) bool {
+	if !isValidDomainASCII(s) {
+		return false
+	}
+	for label := range strings.SplitSeq(s, ".") {
+		if len(label) >= 4 && label[2] == '-' && label[3] == '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTLD turns user input like ".COM" or "рф" into an ASCII TLD label.
+func NormalizeTLD(input string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = strings.Trim(s, ".")
+	if s == "" {
+		return "", fmt.Errorf("empty tld")
+	}
+	if strings.Contains(s, ".") {
+		return "", fmt.Errorf("invalid tld: %q", input)
+	}
+	ascii, err := idna.Lookup.ToASCII(s)
+	if err != nil {
+		return "", fmt.Errorf("idna: %w", err)
+	}
+	if !isValidDomainASCII(ascii + ".x") {
+		return "", fmt.Errorf("invalid tld: %q", input)
+	}
+	return ascii, nil
+}
+
+// IsICANNTLD reports whether an ASCII TLD is delegated in the root zone, per
+// the ICANN section of the embedded public suffix list.
+func IsICANNTLD(tld string) bool {
+	tld = strings.Trim(strings.ToLower(tld), ".")
+	if tld == "" {
+		return false
+	}
+	suffix, icann := publicsuffix.PublicSuffix("x." + tld)
+	return icann && suffix == tld
+}
+
+// IsIDNLabel reports whether a single ASCII label is a punycode A-label.
+func IsIDNLabel(label string) bool {
+	return strings.HasPrefix(strings.ToLower(label), "xn--")
+}
+
+// ToUnicode returns the display (U-label) form of an ASCII domain or label.
+// It falls back to the input if the conversion fails.
+func ToUnicode(ascii string) string {
+	u, err := idna.Lookup.ToUnicode(ascii)
+	if err != nil {
+		return ascii
+	}
+	return u
+}
+
+// StripWWW removes a leading "www." from an ASCII domain unless what remains
+// is a bare public suffix ("www.co.uk" is kept). It reports whether it
+// changed the input.
+func StripWWW(ascii string) (string, bool) {
+	rest, ok := strings.CutPrefix(ascii, "www.")
+	if !ok || !strings.Contains(rest, ".") {
+		return ascii, false
+	}
+	if suffix, _ := publicsuffix.PublicSuffix(rest); suffix == rest {
+		return ascii, false
+	}
+	return rest, true
+}
+
+// Registrable returns the registrable part of an ASCII domain (its ICANN
+// public suffix plus one label): "www.example.co.uk" yields "example.co.uk"
+// and "foo.github.io" yields "github.io", since private suffixes are hosting
+// namespaces, not registries. It errors when the input is itself a suffix.
+func Registrable(ascii string) (string, error) {
+	ascii = strings.TrimSuffix(strings.ToLower(ascii), ".")
+	if ascii == "" || strings.HasPrefix(ascii, ".") || strings.Contains(ascii, "..") {
+		return "", fmt.Errorf("invalid domain %q", ascii)
+	}
+	suffix := icannSuffix(ascii)
+	if len(ascii) <= len(suffix) {
+		return "", fmt.Errorf("%q is a public suffix", ascii)
+	}
+	rest := ascii[:len(ascii)-len(suffix)-1]
+	return rest[strings.LastIndexByte(rest, '.')+1:] + "." + suffix, nil
+}
+
+// icannSuffix returns the public suffix of an ASCII domain, ignoring the
+// private section of the list: rules like github.io are skipped in favor of
+// the ICANN suffix beneath them. Unlisted TLDs yield the last label.
+func icannSuffix(ascii string) string {
+	suffix, icann := publicsuffix.PublicSuffix(ascii)
+	for !icann {
+		i := strings.IndexByte(suffix, '.')
+		if i < 0 {
+			return suffix
+		}
+		suffix, icann = publicsuffix.PublicSuffix(suffix[i+1:])
+	}
+	return suffix
+}
+
+// SuffixCandidates returns the ICANN public suffixes of an ASCII domain,
+// longest first, ending with the top-level label: "example.com.au" yields
+// ["com.au", "au"] and "foo.github.io" yields ["io"]. Registry lookups should
+// try them in that order.
+func SuffixCandidates(ascii string) []string {
+	ascii = strings.TrimSuffix(strings.ToLower(ascii), ".")
+	if ascii == "" {
+		return nil
+	}
+	suffix := icannSuffix(ascii)
+	out := []string{suffix}
+	for {
+		i := strings.IndexByte(suffix, '.')
+		if i < 0 {
+			return out
+		}
+		suffix = suffix[i+1:]
+		out = append(out, suffix)
+	}
+}
+
 func isAllDigits(s string) bool {
 	if s == "" {
 		return false
@@ -86,16 +273,55 @@ func isAllDigits(s string) bool {
 	return true
 }
 
+// ReadLines reads one domain per line, skipping blank lines and "#" comments.
+// Score annotations (see ReadLinesScored) are dropped.
 func ReadLines(r io.Reader) ([]string, error) {
+	lines, err := ReadLinesScored(r)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l.Domain
+	}
+	return out, nil
+}
+
+// ScoredLine is one entry of a hand-curated input list.
+type ScoredLine struct {
+	Domain string
+	Score  int
+	// Scored is false when the line had no "|score" suffix.
+	Scored bool
+}
+
+// ReadLinesScored reads lines like
+//
+//	example.com|90   # favourite
+//
+// Everything from "#" on is a comment, blank lines are skipped, and an
+// optional "|score" suffix sets an integer priority.
+func ReadLinesScored(r io.Reader) ([]ScoredLine, error) {
 	sc := bufio.NewScanner(r)
 	// Domains are short; keep the default scanner buffer.
-	var out []string
+	var out []ScoredLine
+	n := 0
 	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
+		n++
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
-		out = append(out, line)
+		entry := ScoredLine{Domain: line}
+		if d, score, ok := strings.Cut(line, "|"); ok {
+			v, err := strconv.Atoi(strings.TrimSpace(score))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid score %q", n, strings.TrimSpace(score))
+			}
+			entry = ScoredLine{Domain: strings.TrimSpace(d), Score: v, Scored: true}
+		}
+		out = append(out, entry)
 	}
 	if err := sc.Err(); err != nil {
 		return nil, err
@@ -103,6 +329,69 @@ func ReadLines(r io.Reader) ([]string, error) {
 	return out, nil
 }
 
+// ReadCSVColumn reads a CSV document with a header row and returns the
+// non-empty values of one column, selected by header name (case-insensitive)
+// or zero-based index.
+//
+// Malformed rows are skipped and reported in rowErrs instead of aborting the
+// whole read; err is only set when the document can't be read at all.
+func ReadCSVColumn(r io.Reader, column string) (values []string, rowErrs []error, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := -1
+	want := strings.ToLower(strings.TrimSpace(column))
+	for i, h := range header {
+		h = strings.TrimPrefix(h, "\ufeff")
+		if strings.ToLower(strings.TrimSpace(h)) == want {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		n, convErr := strconv.Atoi(want)
+		if convErr != nil || n < 0 {
+			return nil, nil, fmt.Errorf("csv column %q not found in header", column)
+		}
+		idx = n
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				rowErrs = append(rowErrs, err)
+				continue
+			}
+			return nil, nil, err
+		}
+		if idx >= len(rec) {
+			line, _ := cr.FieldPos(0)
+			rowErrs = append(rowErrs, fmt.Errorf("csv line %d: missing column %q", line, column))
+			continue
+		}
+		v := strings.TrimSpace(rec[idx])
+		if v == "" {
+			continue
+		}
+		values = append(values, v)
+	}
+	return values, rowErrs, nil
+}
+
 func NewTabWriter(w io.Writer) *tabwriter.Writer {
 	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 }
@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestNormalize(t *testing.T) {
 	t.Parallel()
@@ -37,3 +41,261 @@ func TestNormalize(t *testing.T) {
 		}
 	}
 }
+
+func TestIsIDNLabel(t *testing.T) {
+	t.Parallel()
+
+	if !IsIDNLabel("xn--p1ai") {
+		t.Fatalf("IsIDNLabel(xn--p1ai)=false, want true")
+	}
+	if IsIDNLabel("com") {
+		t.Fatalf("IsIDNLabel(com)=true, want false")
+	}
+	if got := ToUnicode("xn--p1ai"); got != "рф" {
+		t.Fatalf("ToUnicode(xn--p1ai)=%q, want рф", got)
+	}
+}
+
+func TestReadCSVColumn(t *testing.T) {
+	t.Parallel()
+
+	in := strings.Join([]string{
+		"name,Domain,notes",
+		"one,example.com,first",
+		"two,,empty",
+		"three",
+		`four,"bad"quote,x`,
+		"five, example.org ,last",
+	}, "\n")
+
+	got, rowErrs, err := ReadCSVColumn(strings.NewReader(in), "domain")
+	if err != nil {
+		t.Fatalf("ReadCSVColumn: %v", err)
+	}
+	if strings.Join(got, ",") != "example.com,example.org" {
+		t.Fatalf("values=%v, want [example.com example.org]", got)
+	}
+	if len(rowErrs) != 2 {
+		t.Fatalf("rowErrs=%v, want 2 malformed rows", rowErrs)
+	}
+
+	got, _, err = ReadCSVColumn(strings.NewReader(in), "1")
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ReadCSVColumn(index 1)=%v, %v; want 2 values", got, err)
+	}
+
+	if _, _, err := ReadCSVColumn(strings.NewReader(in), "missing"); err == nil {
+		t.Fatalf("ReadCSVColumn(missing): expected error")
+	}
+}
+
+func TestNormalizeTLD(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{".COM": "com", " io ": "io", "рф": "xn--p1ai"} {
+		got, err := NormalizeTLD(in)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeTLD(%q)=%q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "co.uk", "-bad"} {
+		if _, err := NormalizeTLD(in); err == nil {
+			t.Fatalf("NormalizeTLD(%q): expected error", in)
+		}
+	}
+}
+
+func TestRegistrable(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"www.example.co.uk": "example.co.uk",
+		"Example.COM.":      "example.com",
+		"foo.github.io":     "github.io",
+		"x.blogspot.com":    "blogspot.com",
+		"a.b.example.zzzz":  "example.zzzz",
+	}
+	for in, want := range tests {
+		if got, err := Registrable(in); err != nil || got != want {
+			t.Fatalf("Registrable(%q)=%q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "co.uk", "com", ".example.com", "a..com"} {
+		if got, err := Registrable(in); err == nil {
+			t.Fatalf("Registrable(%q)=%q, want error", in, got)
+		}
+	}
+}
+
+func TestSuffixCandidates(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"example.com":       {"com"},
+		"example.com.au":    {"com.au", "au"},
+		"www.example.co.uk": {"co.uk", "uk"},
+		"example.unlisted":  {"unlisted"},
+		"foo.github.io":     {"io"},
+		"x.blogspot.com":    {"com"},
+	}
+	for in, want := range tests {
+		got := SuffixCandidates(in)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("SuffixCandidates(%q)=%v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestStripWWW(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"www.example.com":   "example.com",
+		"www.example.co.uk": "example.co.uk",
+		"www.co.uk":         "www.co.uk",
+		"www.com":           "www.com",
+		"wwwexample.com":    "wwwexample.com",
+		"shop.example.com":  "shop.example.com",
+	}
+	for in, want := range tests {
+		got, changed := StripWWW(in)
+		if got != want || changed != (in != want) {
+			t.Fatalf("StripWWW(%q)=%q, %v; want %q", in, got, changed, want)
+		}
+	}
+}
+
+func TestIsNormalized(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"openai.com":         true,
+		"a-b.co.uk":          true,
+		"123.456":            true,
+		"xn--80ak6aa92e.com": false, // valid, but punycode needs the full check
+		"xn--abc.com":        false,
+		"ab--cd.com":         false,
+		"OpenAI.com":         false,
+		"openai.com.":        false,
+		" openai.com":        false,
+		"café.com":           false,
+		"localhost":          false,
+	}
+	for in, want := range tests {
+		if got := IsNormalized(in); got != want {
+			t.Fatalf("IsNormalized(%q)=%v, want %v", in, got, want)
+		}
+		if want {
+			if got, err := Normalize(in); err != nil || got != in {
+				t.Fatalf("Normalize(%q)=%q, %v; want unchanged", in, got, err)
+			}
+		}
+	}
+
+	// Skipping the fast path must still reject what IDNA rejects.
+	for _, in := range []string{"ab--cd.com", "xn--abc.com"} {
+		if _, err := Normalize(in); err == nil {
+			t.Fatalf("Normalize(%q): expected error", in)
+		}
+	}
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = "candidate-label-" + strings.Repeat("x", i%20) + ".com"
+	}
+
+	b.Run("normalized", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, in := range inputs {
+				_, _ = Normalize(in)
+			}
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, in := range inputs {
+				_, _ = Normalize(" https://" + in + "/")
+			}
+		}
+	})
+}
+
+func TestIsICANNTLD(t *testing.T) {
+	t.Parallel()
+
+	for tld, want := range map[string]bool{
+		"com":      true,
+		"COM":      true,
+		"de":       true,
+		"xn--p1ai": true,
+		"con":      false,
+		"":         false,
+	} {
+		if got := IsICANNTLD(tld); got != want {
+			t.Fatalf("IsICANNTLD(%q)=%v, want %v", tld, got, want)
+		}
+	}
+}
+
+func TestNormalizeWithProfile(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in      string
+		profile Profile
+		want    string // "" means an error
+	}{
+		{"ⅷ.com", ProfileLookup, "viii.com"},
+		{"ⅷ.com", ProfileRegistration, ""},
+		{"ⅷ.com", ProfilePunycode, "xn--e5g.com"},
+		{"ab--cd.com", ProfileLookup, ""},
+		{"ab--cd.com", ProfilePunycode, "ab--cd.com"},
+		{"bücher.de", ProfileRegistration, "xn--bcher-kva.de"},
+		{"ⅷ.com", "", "viii.com"},
+	} {
+		got, err := NormalizeWithProfile(tc.in, tc.profile)
+		if tc.want == "" {
+			if err == nil {
+				t.Fatalf("NormalizeWithProfile(%q, %q)=%q, want error", tc.in, tc.profile, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Fatalf("NormalizeWithProfile(%q, %q)=%q, %v, want %q", tc.in, tc.profile, got, err, tc.want)
+		}
+	}
+
+	if _, err := ParseProfile("loose"); err == nil {
+		t.Fatal("ParseProfile(loose): want error")
+	}
+}
+
+func TestReadLinesScored(t *testing.T) {
+	t.Parallel()
+
+	in := "# shortlist\nexample.com  # my favorite\n\nfoo.io|90\nbar.dev | -5 # meh\nhttps://baz.app/#top\n"
+	got, err := ReadLinesScored(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ReadLinesScored: %v", err)
+	}
+	want := []ScoredLine{
+		{Domain: "example.com"},
+		{Domain: "foo.io", Score: 90, Scored: true},
+		{Domain: "bar.dev", Score: -5, Scored: true},
+		{Domain: "https://baz.app/"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	lines, err := ReadLines(strings.NewReader(in))
+	if err != nil || strings.Join(lines, ",") != "example.com,foo.io,bar.dev,https://baz.app/" {
+		t.Fatalf("ReadLines=%q, %v, want domains without annotations", lines, err)
+	}
+
+	if _, err := ReadLinesScored(strings.NewReader("ok.com\nfoo.io|high\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err=%v, want line 2 invalid score", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package history persists availability results to SQLite so periodic sweeps
+// can be compared over time.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS results (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain     TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	method     TEXT NOT NULL DEFAULT '',
+	confidence TEXT NOT NULL DEFAULT '',
+	price      TEXT NOT NULL DEFAULT '',
+	checked_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_domain_checked_at ON results (domain, checked_at);
+`
+
+// Entry is one stored result.
+type Entry struct {
+	Domain     string `json:"domain"`
+	Status     string `json:"status"`
+	Method     string `json:"method"`
+	Confidence string `json:"confidence"`
+	Price      string `json:"price,omitempty"`
+	CheckedAt  string `json:"checked_at"`
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the history database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("empty history db path")
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time; a single connection avoids
+	// "database is locked" errors within this process.
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init history db %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append stores results in a single transaction. Results with an empty
+// Domain are skipped; inputs that failed normalization carry the raw input
+// there, so callers should leave those out.
+func (s *Store) Append(ctx context.Context, results []availability.Result) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO results (domain, status, method, confidence, price, checked_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range results {
+		if r.Domain == "" {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, r.Domain, string(r.Status), string(r.Method), r.Confidence, r.Price, r.CheckedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Timeline returns the stored results for a domain, oldest first.
+func (s *Store) Timeline(ctx context.Context, domain string) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT domain, status, method, confidence, price, checked_at FROM results WHERE domain = ? ORDER BY checked_at, id`, domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Domain, &e.Status, &e.Method, &e.Confidence, &e.Price, &e.CheckedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestStore_AppendAndTimeline(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	s, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	err = s.Append(ctx, []availability.Result{
+		{Domain: "example.com", Status: availability.StatusTaken, Method: availability.MethodRDAP, Confidence: "high", CheckedAt: "2026-01-02T00:00:00Z"},
+		{Domain: "other.com", Status: availability.StatusAvailable, CheckedAt: "2026-01-02T00:00:00Z"},
+		{Input: "bad..input", Status: availability.StatusUnknown},
+	})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopen to make sure rows survive and the schema init is idempotent.
+	s, err = Open(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	err = s.Append(ctx, []availability.Result{
+		{Domain: "example.com", Status: availability.StatusAvailable, Method: availability.MethodWHOIS, Confidence: "medium", Price: "9.99", CheckedAt: "2026-01-01T00:00:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	got, err := s.Timeline(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len=%d, want 2 (%v)", len(got), got)
+	}
+	if got[0].Status != "available" || got[0].Price != "9.99" || got[1].Status != "taken" || got[1].Method != "rdap" {
+		t.Fatalf("Timeline=%+v, want oldest first", got)
+	}
+}
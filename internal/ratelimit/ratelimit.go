@@ -0,0 +1,74 @@
+// Package ratelimit paces outgoing requests: a token bucket, and the keyed
+// Limiter interface the lookup clients wait on.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+)
+
+// Limiter paces requests per key, such as a TLD. *availability.RegistryLimiter
+// implements it for the RDAP and WHOIS clients.
+type Limiter interface {
+	Wait(ctx context.Context, key string) error
+}
+
+// Bucket is a token bucket. Shared by every request of one kind in a run (all
+// registrar calls, say), it keeps the aggregate rate under budget regardless
+// of worker count. A nil *Bucket never waits.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	clock clock.Clock
+}
+
+// NewBucket allows perSecond requests per second with bursts of up to burst
+// requests. It returns nil (unlimited) when perSecond <= 0.
+func NewBucket(perSecond float64, burst int) *Bucket {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		clock:  clock.Real{},
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *Bucket) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	// Reserve a token now; a negative balance is paid back by waiting, which
+	// keeps concurrent callers queued in order.
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	return l.clock.Sleep(ctx, wait)
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+)
+
+func TestBucket_Wait(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := NewBucket(2, 1)
+	l.clock = clk
+
+	// Three back-to-back requests at 2/s: the first uses the burst token, the
+	// next two are 500ms apart.
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if want := "[500ms 500ms]"; fmt.Sprint(clk.Sleeps()) != want {
+		t.Fatalf("waits=%v, want %s", clk.Sleeps(), want)
+	}
+
+	// After a full second without requests, one is free again.
+	clk.Advance(time.Second)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if got := len(clk.Sleeps()); got != 2 {
+		t.Fatalf("waits=%v, want no new wait after refill", clk.Sleeps())
+	}
+}
+
+func TestBucket_NilIsUnlimited(t *testing.T) {
+	t.Parallel()
+
+	l := NewBucket(0, 1)
+	if l != nil {
+		t.Fatalf("NewBucket(0)=%v, want nil", l)
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("nil Wait: %v", err)
+	}
+}
@@ -1,17 +1,28 @@
 package rdap
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/ratelimit"
+	"golang.org/x/sync/singleflight"
 )
 
 const DefaultBootstrapURL = "https://data.iana.org/rdap/dns.json"
@@ -22,8 +33,38 @@ type Options struct {
 	CacheTTL     time.Duration
 	Timeout      time.Duration
 	Verbose      bool
+
+	// NoDiskCache keeps the bootstrap in memory only: CacheDir is neither
+	// read nor written.
+	NoDiskCache bool
+
+	// HTTPClient, if set, is used instead of a client built from Timeout
+	// (useful for tests and benchmarks).
+	HTTPClient *http.Client
+
+	// URLOverrides maps a TLD or public suffix to an RDAP base URL that is
+	// tried before (and without needing) the bootstrap's services.
+	URLOverrides map[string]string
+
+	// Retries is how many times a server is re-asked right away after a
+	// connection-level failure (DNS error, refused or reset connection)
+	// before failing over to the next one. 0 means 1; negative disables.
+	Retries int
+
+	// Limiter, if set, is waited on before every domain request, keyed by
+	// the domain's TLD. Sharing one with the WHOIS client paces both methods
+	// against the same registry.
+	Limiter ratelimit.Limiter
 }
 
+// retryDelay is the pause before a connection retry: long enough to ride out
+// a blip, short enough not to matter next to a lookup timeout.
+const retryDelay = 100 * time.Millisecond
+
+// ErrMaintenance is set (wrapped) in Evidence.Err when a server answers with
+// a registry maintenance page: a 503, or a 200 that isn't RDAP JSON.
+var ErrMaintenance = errors.New("registry maintenance")
+
 type Client struct {
 	opts Options
 	http *http.Client
@@ -39,6 +80,29 @@ type Evidence struct {
 	URL        string
 	HTTPStatus int
 	Err        error
+
+	// DomainStatus holds the RDAP status values (e.g. "redemption period")
+	// from a 200 response body, when present.
+	DomainStatus []string
+
+	// Variants lists IDN variant names from a 200 response body, each with
+	// its relation to the domain, e.g. "xn--fo-cka.example (registered,
+	// conjoined)". Empty for non-IDN domains.
+	Variants []string
+
+	// Attempts is how many RDAP requests were sent (failover and connection
+	// retries included).
+	Attempts int
+
+	// Privacy reports whether registrant details are redacted or
+	// privacy-protected. Nil when the response carries no such signal.
+	Privacy *bool
+
+	// BytesRead and ServerLatencyMs describe the last request: response
+	// body bytes read off the wire, and milliseconds from sending the
+	// request to its response headers (limiter waits excluded).
+	BytesRead       int
+	ServerLatencyMs int64
 }
 
 func NewClient(opts Options) *Client {
@@ -51,21 +115,41 @@ func NewClient(opts Options) *Client {
 	if opts.Timeout == 0 {
 		opts.Timeout = 8 * time.Second
 	}
-	if opts.CacheDir == "" {
+	if opts.Retries == 0 {
+		opts.Retries = 1
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.NoDiskCache {
+		opts.CacheDir = ""
+	} else if opts.CacheDir == "" {
 		if d, err := os.UserCacheDir(); err == nil && d != "" {
 			opts.CacheDir = filepath.Join(d, "dothuntcli")
 		}
 	}
 
+	httpc := opts.HTTPClient
+	if httpc == nil {
+		httpc = &http.Client{Timeout: opts.Timeout}
+	}
+
+	if len(opts.URLOverrides) > 0 {
+		overrides := make(map[string]string, len(opts.URLOverrides))
+		for k, v := range opts.URLOverrides {
+			overrides[strings.Trim(strings.ToLower(strings.TrimSpace(k)), ".")] = strings.TrimSpace(v)
+		}
+		opts.URLOverrides = overrides
+	}
+
 	return &Client{
 		opts: opts,
-		http: &http.Client{Timeout: opts.Timeout},
+		http: httpc,
 	}
 }
 
 func (c *Client) LookupDomain(ctx context.Context, domain string) Evidence {
-	tld := lastLabel(domain)
-	if tld == "" {
+	if lastLabel(domain) == "" {
 		return Evidence{
 			Status:     "unknown",
 			Confidence: "low",
@@ -74,8 +158,9 @@ func (c *Client) LookupDomain(ctx context.Context, domain string) Evidence {
 		}
 	}
 
+	urls := c.overrideURLs(domain)
 	bs, err := c.getBootstrap(ctx)
-	if err != nil {
+	if err != nil && len(urls) == 0 {
 		return Evidence{
 			Status:     "unknown",
 			Confidence: "low",
@@ -83,8 +168,15 @@ func (c *Client) LookupDomain(ctx context.Context, domain string) Evidence {
 			Err:        err,
 		}
 	}
-
-	urls := bs.urlsForTLD(tld)
+	if err == nil {
+		// Bootstrap services follow the override in failover order.
+		for _, u := range bs.urlsForDomain(domain) {
+			if len(urls) > 0 && strings.TrimRight(u, "/") == strings.TrimRight(urls[0], "/") {
+				continue
+			}
+			urls = append(urls, u)
+		}
+	}
 	if len(urls) == 0 {
 		return Evidence{
 			Status:     "unknown",
@@ -94,27 +186,141 @@ func (c *Client) LookupDomain(ctx context.Context, domain string) Evidence {
 	}
 
 	var lastErr error
+	var last Evidence
+	attempts := 0
 	for _, base := range urls {
-		ev := c.lookupOne(ctx, base, domain)
+		var ev Evidence
+		for try := 0; ; try++ {
+			ev = c.lookupOne(ctx, base, domain)
+			attempts++
+			if try >= c.opts.Retries || !retryableNetErr(ev.Err) || sleepContext(ctx, retryDelay) != nil {
+				break
+			}
+		}
+		ev.Attempts = attempts
 		if ev.Status != "unknown" {
 			return ev
 		}
 		if ev.Err != nil {
 			lastErr = ev.Err
 		}
+		last = ev
 	}
 
+	reason := "rdap lookup failed"
+	if errors.Is(lastErr, ErrMaintenance) {
+		reason = "registry maintenance"
+	}
 	return Evidence{
 		Status:     "unknown",
 		Confidence: "low",
-		Reason:     "rdap lookup failed",
+		Reason:     reason,
+		URL:        last.URL,
+		HTTPStatus: last.HTTPStatus,
+		Attempts:   last.Attempts,
 		Err:        lastErr,
+
+		BytesRead:       last.BytesRead,
+		ServerLatencyMs: last.ServerLatencyMs,
 	}
 }
 
+// LookupAlternate queries domain at the first bootstrap service other than
+// the one that served usedURL (a previous Evidence.URL), for confirming an
+// answer against an independent mirror. Status is "unknown" with Reason
+// "no alternate rdap service" when the TLD lists only one service.
+func (c *Client) LookupAlternate(ctx context.Context, domain, usedURL string) Evidence {
+	bs, err := c.getBootstrap(ctx)
+	if err != nil {
+		return Evidence{Status: "unknown", Confidence: "low", Reason: "rdap bootstrap unavailable", Err: err}
+	}
+	for _, base := range bs.urlsForDomain(domain) {
+		if usedURL != "" && strings.HasPrefix(usedURL, strings.TrimRight(base, "/")+"/") {
+			continue
+		}
+		ev := c.lookupOne(ctx, base, domain)
+		ev.Attempts = 1
+		return ev
+	}
+	return Evidence{Status: "unknown", Confidence: "low", Reason: "no alternate rdap service"}
+}
+
+// retryableNetErr reports whether err is a connection-level failure worth an
+// immediate retry: DNS trouble or a refused/reset connection. Timeouts and
+// NXDOMAIN for the server's host are not retried.
+func retryableNetErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial" && !opErr.Timeout()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// overrideURLs returns the URLOverrides entry for the longest matching
+// suffix of domain, if any.
+func (c *Client) overrideURLs(name string) []string {
+	if len(c.opts.URLOverrides) == 0 {
+		return nil
+	}
+	for _, suffix := range domain.SuffixCandidates(name) {
+		if u, ok := c.opts.URLOverrides[suffix]; ok {
+			return []string{u}
+		}
+	}
+	return nil
+}
+
+// Prefetch loads the RDAP bootstrap, fetching and caching it on disk when the
+// cached copy is missing or stale.
+func (c *Client) Prefetch(ctx context.Context) error {
+	_, err := c.getBootstrap(ctx)
+	return err
+}
+
+// TLDs returns the TLDs the bootstrap registry lists RDAP services for,
+// sorted.
+func (c *Client) TLDs(ctx context.Context) ([]string, error) {
+	bs, err := c.getBootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tlds := make([]string, 0, len(bs.tldToURLs))
+	for tld := range bs.tldToURLs {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	return tlds, nil
+}
+
+// ServiceURLs returns the bootstrap's RDAP base URLs for a TLD.
+func (c *Client) ServiceURLs(ctx context.Context, tld string) ([]string, error) {
+	bs, err := c.getBootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bs.urlsForTLD(tld), nil
+}
+
 func (c *Client) lookupOne(ctx context.Context, base, domain string) Evidence {
-	base = strings.TrimRight(base, "/")
-	rdapURL := base + "/domain/" + url.PathEscape(domain)
+	rdapURL := domainURL(base, domain)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rdapURL, nil)
 	if err != nil {
@@ -122,23 +328,90 @@ func (c *Client) lookupOne(ctx context.Context, base, domain string) Evidence {
 	}
 	req.Header.Set("accept", "application/rdap+json, application/json")
 
+	if c.opts.Limiter != nil {
+		if err := c.opts.Limiter.Wait(ctx, lastLabel(domain)); err != nil {
+			return Evidence{Status: "unknown", Confidence: "low", Reason: "network error", URL: rdapURL, Err: err}
+		}
+	}
+	start := time.Now()
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return Evidence{Status: "unknown", Confidence: "low", Reason: "network error", URL: rdapURL, Err: err}
 	}
+	latency := time.Since(start)
 	defer resp.Body.Close()
-	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
+	body := &countingReader{r: resp.Body}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}
+
+	ev := evidenceFor(resp, rdapURL)
+	ev.BytesRead = body.n
+	ev.ServerLatencyMs = latency.Milliseconds()
+	return ev
+}
+
+// domainURL builds the RDAP domain query URL for base. A misbuilt URL gets a
+// 404, which reads as "available", so it is defensive about its inputs:
+//
+//   - the domain is lower-cased and loses any trailing dot (A-labels stay
+//     as they are; they're plain LDH and need no escaping);
+//   - any number of trailing slashes on base collapse to one separator;
+//   - a base that already ends in "/domain" (a common mistake in overrides)
+//     isn't given a second one;
+//   - a query string on base is kept after the path.
+func domainURL(base, domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	base, query, hasQuery := strings.Cut(strings.TrimSpace(base), "?")
+	base = strings.TrimRight(base, "/")
+	if strings.HasSuffix(strings.ToLower(base), "/domain") {
+		base = base[:len(base)-len("/domain")]
+	}
+	u := base + "/domain/" + url.PathEscape(domain)
+	if hasQuery && query != "" {
+		u += "?" + query
+	}
+	return u
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
 
+// evidenceFor classifies a domain response and consumes (part of) its body.
+func evidenceFor(resp *http.Response, rdapURL string) Evidence {
 	switch resp.StatusCode {
 	case http.StatusOK:
+		// The status code alone decides "taken"; the body only adds detail.
+		var body []byte
+		if r, err := decodedBody(resp); err == nil {
+			body, _ = io.ReadAll(io.LimitReader(r, maxDomainBodyBytes))
+		}
+		if !json.Valid(body) && isMaintenance(body) {
+			return maintenanceEvidence(resp, rdapURL)
+		}
+		info := parseDomain(body)
 		return Evidence{
-			Status:     "taken",
-			Confidence: "high",
-			Reason:     "rdap 200",
-			URL:        rdapURL,
-			HTTPStatus: resp.StatusCode,
+			Status:       "taken",
+			Confidence:   "high",
+			Reason:       "rdap 200",
+			URL:          rdapURL,
+			HTTPStatus:   resp.StatusCode,
+			DomainStatus: info.Status,
+			Variants:     info.variants(),
+			Privacy:      info.privacy(),
 		}
 	case http.StatusNotFound:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
 		return Evidence{
 			Status:     "available",
 			Confidence: "high",
@@ -146,7 +419,21 @@ func (c *Client) lookupOne(ctx context.Context, base, domain string) Evidence {
 			URL:        rdapURL,
 			HTTPStatus: resp.StatusCode,
 		}
+	case http.StatusServiceUnavailable:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		if isMaintenance(body) {
+			return maintenanceEvidence(resp, rdapURL)
+		}
+		return Evidence{
+			Status:     "unknown",
+			Confidence: "low",
+			Reason:     "rdap http 503",
+			URL:        rdapURL,
+			HTTPStatus: resp.StatusCode,
+			Err:        fmt.Errorf("rdap http 503"),
+		}
 	default:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
 		return Evidence{
 			Status:     "unknown",
 			Confidence: "low",
@@ -158,18 +445,214 @@ func (c *Client) lookupOne(ctx context.Context, base, domain string) Evidence {
 	}
 }
 
+const maxDomainBodyBytes = 1 << 20
+
+// maxErrorBodyBytes bounds how much of an error page is read to look for a
+// maintenance notice.
+const maxErrorBodyBytes = 4 << 10
+
+func isMaintenance(body []byte) bool {
+	return bytes.Contains(bytes.ToLower(body), []byte("maintenance"))
+}
+
+// maintenanceEvidence reports a registry maintenance page: unknown, and
+// never read as an answer about the domain.
+func maintenanceEvidence(resp *http.Response, rdapURL string) Evidence {
+	return Evidence{
+		Status:     "unknown",
+		Confidence: "low",
+		Reason:     "registry maintenance",
+		URL:        rdapURL,
+		HTTPStatus: resp.StatusCode,
+		Err:        fmt.Errorf("rdap http %d: %w", resp.StatusCode, ErrMaintenance),
+	}
+}
+
+// decodedBody returns resp.Body, decompressing it when the transport left a
+// gzip/deflate Content-Encoding in place (custom transports, or servers that
+// compress without being asked). The default transport's own gzip handling
+// sets resp.Uncompressed and is passed through.
+func decodedBody(resp *http.Response) (io.Reader, error) {
+	if resp.Uncompressed {
+		return resp.Body, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(resp.Body)
+	case "deflate":
+		// HTTP "deflate" is zlib-wrapped.
+		return zlib.NewReader(resp.Body)
+	default:
+		return resp.Body, nil
+	}
+}
+
+// domainInfo is the subset of an RDAP domain object we use.
+type domainInfo struct {
+	Status   []string     `json:"status"`
+	Redacted []redaction  `json:"redacted"`
+	Remarks  []remark     `json:"remarks"`
+	Entities []entityInfo `json:"entities"`
+	Variants []variant    `json:"variants"`
+}
+
+// variant is an RFC 9083 IDN variant group: names sharing one relation to
+// the queried domain.
+type variant struct {
+	Relation     []string `json:"relation"`
+	VariantNames []struct {
+		LDHName     string `json:"ldhName"`
+		UnicodeName string `json:"unicodeName"`
+	} `json:"variantNames"`
+}
+
+// redaction is an RFC 9537 "redacted" member.
+type redaction struct {
+	Name struct {
+		Description string `json:"description"`
+		Type        string `json:"type"`
+	} `json:"name"`
+}
+
+type remark struct {
+	Title       string   `json:"title"`
+	Description []string `json:"description"`
+}
+
+type entityInfo struct {
+	Roles   []string `json:"roles"`
+	Remarks []remark `json:"remarks"`
+}
+
+// privacy returns true when registrant data is redacted (RFC 9537) or a
+// remark mentions privacy/proxy/redaction, false when the server emits
+// redaction info that doesn't cover the registrant, and nil otherwise.
+func (d domainInfo) privacy() *bool {
+	for _, r := range d.Redacted {
+		name := strings.ToLower(r.Name.Description + " " + r.Name.Type)
+		if strings.Contains(name, "registrant") {
+			return boolPtr(true)
+		}
+	}
+	if hasPrivacyRemark(d.Remarks) {
+		return boolPtr(true)
+	}
+	for _, e := range d.Entities {
+		for _, role := range e.Roles {
+			if strings.EqualFold(role, "registrant") && hasPrivacyRemark(e.Remarks) {
+				return boolPtr(true)
+			}
+		}
+	}
+	if len(d.Redacted) > 0 {
+		return boolPtr(false)
+	}
+	return nil
+}
+
+// variants flattens the variant groups into one entry per name, preferring
+// the Unicode form and noting the relation (registered, blocked, ...).
+func (d domainInfo) variants() []string {
+	var out []string
+	for _, v := range d.Variants {
+		rel := strings.Join(v.Relation, ", ")
+		for _, n := range v.VariantNames {
+			name := strings.TrimSpace(n.UnicodeName)
+			if name == "" {
+				name = strings.TrimSpace(n.LDHName)
+			}
+			if name == "" {
+				continue
+			}
+			if rel != "" {
+				name += " (" + rel + ")"
+			}
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func hasPrivacyRemark(remarks []remark) bool {
+	for _, r := range remarks {
+		text := strings.ToLower(r.Title + " " + strings.Join(r.Description, " "))
+		for _, kw := range []string{"redacted", "privacy", "proxy"} {
+			if strings.Contains(text, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+// parseDomain extracts optional details from an RDAP domain object. Malformed
+// or partial bodies yield an empty domainInfo rather than an error, and
+// members are decoded one at a time, so a missing or oddly typed one (a
+// string where an array belongs, say) costs only that detail. The 200 status
+// code alone has already decided "taken".
+func parseDomain(b []byte) domainInfo {
+	var info domainInfo
+	var members map[string]json.RawMessage
+	if len(b) == 0 || json.Unmarshal(b, &members) != nil {
+		return info
+	}
+	decodeMember(members, "status", &info.Status)
+	decodeMember(members, "redacted", &info.Redacted)
+	decodeMember(members, "remarks", &info.Remarks)
+	decodeMember(members, "entities", &info.Entities)
+	decodeMember(members, "variants", &info.Variants)
+	return info
+}
+
+// decodeMember sets *dst from members[name], leaving it zero when the member
+// is absent or doesn't decode.
+func decodeMember[T any](members map[string]json.RawMessage, name string, dst *T) {
+	raw, ok := members[name]
+	if !ok {
+		return
+	}
+	var v T
+	if json.Unmarshal(raw, &v) == nil {
+		*dst = v
+	}
+}
+
+// bootstrapLoads shares one in-flight bootstrap load between all callers in
+// the process that use the same source and cache file, including separate
+// Clients, so the multi-megabyte file isn't fetched more than once at a time.
+var bootstrapLoads singleflight.Group
+
 func (c *Client) getBootstrap(ctx context.Context) (*bootstrap, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.bootstrap != nil {
-		return c.bootstrap, nil
+	bs := c.bootstrap
+	c.mu.Unlock()
+	if bs != nil {
+		return bs, nil
 	}
 
-	bs, err := loadBootstrap(ctx, c.http, c.opts.BootstrapURL, c.cachePath(), c.opts.CacheTTL)
-	if err != nil {
-		return nil, err
+	key := c.opts.BootstrapURL + "\x00" + c.cachePath()
+	ch := bootstrapLoads.DoChan(key, func() (any, error) {
+		// The load outlives any one caller: each waiter below gives up on
+		// its own ctx, and the HTTP client's timeout bounds the fetch.
+		return loadBootstrap(context.WithoutCancel(ctx), c.http, c.opts.BootstrapURL, c.cachePath(), c.opts.CacheTTL)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		bs = res.Val.(*bootstrap)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bootstrap == nil {
+		c.bootstrap = bs
 	}
-	c.bootstrap = bs
 	return c.bootstrap, nil
 }
 
@@ -188,6 +671,17 @@ func (b *bootstrap) urlsForTLD(tld string) []string {
 	return b.tldToURLs[strings.ToLower(tld)]
 }
 
+// urlsForDomain prefers a service registered for a multi-level public suffix
+// (e.g. "com.au") over the one for the top-level label.
+func (b *bootstrap) urlsForDomain(name string) []string {
+	for _, suffix := range domain.SuffixCandidates(name) {
+		if urls := b.urlsForTLD(suffix); len(urls) > 0 {
+			return urls
+		}
+	}
+	return nil
+}
+
 type bootstrapJSON struct {
 	Services [][][]string `json:"services"`
 }
@@ -1,6 +1,23 @@
 package rdap
 
-import "testing"
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
 
 func TestParseBootstrap(t *testing.T) {
 	t.Parallel()
@@ -23,3 +40,452 @@ func TestParseBootstrap(t *testing.T) {
 		t.Fatalf("urlsForTLD(de)=%v", got)
 	}
 }
+
+func TestBootstrap_URLsForDomain_PrefersMultiLevelSuffix(t *testing.T) {
+	t.Parallel()
+
+	b, err := parseBootstrap([]byte(`{
+  "services": [
+    [["au"], ["https://rdap.au/"]],
+    [["com.au"], ["https://rdap.com-au/"]]
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("parseBootstrap: %v", err)
+	}
+
+	if got := b.urlsForDomain("example.com.au"); len(got) != 1 || got[0] != "https://rdap.com-au/" {
+		t.Fatalf("urlsForDomain(example.com.au)=%v, want com.au service", got)
+	}
+	if got := b.urlsForDomain("example.net.au"); len(got) != 1 || got[0] != "https://rdap.au/" {
+		t.Fatalf("urlsForDomain(example.net.au)=%v, want au service", got)
+	}
+}
+
+func TestClient_LookupDomain_HTTPClient(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+		case "/rdap/domain/taken.com":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+	})
+
+	if ev := c.LookupDomain(context.Background(), "taken.com"); ev.Status != "taken" {
+		t.Fatalf("taken.com status=%q (%v), want taken", ev.Status, ev.Err)
+	}
+	if ev := c.LookupDomain(context.Background(), "free.com"); ev.Status != "available" {
+		t.Fatalf("free.com status=%q (%v), want available", ev.Status, ev.Err)
+	}
+}
+
+func TestClient_LookupDomain_URLOverride(t *testing.T) {
+	t.Parallel()
+
+	var hits []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/bootstrap/"]]]}`, srv.URL)
+		case "/mirror/domain/example.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/bootstrap/domain/example.com":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+		URLOverrides: map[string]string{"COM": srv.URL + "/mirror/"},
+	})
+
+	ev := c.LookupDomain(context.Background(), "example.com")
+	if ev.Status != "available" {
+		t.Fatalf("status=%q (%v), want available via bootstrap failover", ev.Status, ev.Err)
+	}
+	want := []string{"/dns.json", "/mirror/domain/example.com", "/bootstrap/domain/example.com"}
+	if fmt.Sprint(hits) != fmt.Sprint(want) {
+		t.Fatalf("hits=%v, want override tried first: %v", hits, want)
+	}
+}
+
+func TestClient_LookupDomain_CompressedBody(t *testing.T) {
+	t.Parallel()
+
+	body := `{"objectClassName":"domain","status":["pending delete"]}`
+	var gz, zl bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, _ = io.WriteString(gw, body)
+	_ = gw.Close()
+	zw := zlib.NewWriter(&zl)
+	_, _ = io.WriteString(zw, body)
+	_ = zw.Close()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+		case "/rdap/domain/gzip.com":
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(gz.Bytes())
+		case "/rdap/domain/deflate.com":
+			w.Header().Set("Content-Encoding", "deflate")
+			_, _ = w.Write(zl.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	for _, disable := range []bool{false, true} {
+		httpc := srv.Client()
+		httpc.Transport.(*http.Transport).DisableCompression = disable
+		c := NewClient(Options{
+			BootstrapURL: srv.URL + "/dns.json",
+			CacheDir:     t.TempDir(),
+			HTTPClient:   httpc,
+		})
+		for _, d := range []string{"gzip.com", "deflate.com"} {
+			ev := c.LookupDomain(context.Background(), d)
+			if ev.Status != "taken" || len(ev.DomainStatus) != 1 || ev.DomainStatus[0] != "pending delete" {
+				t.Fatalf("%s (DisableCompression=%v): status=%q DomainStatus=%v, want parsed body", d, disable, ev.Status, ev.DomainStatus)
+			}
+		}
+	}
+}
+
+func TestParseDomain_Status(t *testing.T) {
+	t.Parallel()
+
+	info := parseDomain([]byte(`{"objectClassName":"domain","ldhName":"example.com","status":["client transfer prohibited","redemption period"]}`))
+	if len(info.Status) != 2 || info.Status[1] != "redemption period" {
+		t.Fatalf("Status=%v, want parsed", info.Status)
+	}
+
+	if info := parseDomain([]byte(`not json`)); len(info.Status) != 0 {
+		t.Fatalf("Status=%v, want empty for malformed body", info.Status)
+	}
+}
+
+func TestParseDomain_PartialObject(t *testing.T) {
+	t.Parallel()
+
+	info := parseDomain([]byte(`{"objectClassName":"domain","ldhName":"example.com"}`))
+	if info.Status != nil || info.privacy() != nil || info.variants() != nil {
+		t.Fatalf("info=%+v, want no details from a stripped-down object", info)
+	}
+
+	// A mistyped member loses only itself.
+	info = parseDomain([]byte(`{"status":"active","redacted":[{"name":{"type":"Registrant Name"}}],"entities":{}}`))
+	if info.Status != nil || info.Entities != nil {
+		t.Fatalf("Status=%v Entities=%v, want mistyped members dropped", info.Status, info.Entities)
+	}
+	if p := info.privacy(); p == nil || !*p {
+		t.Fatalf("privacy()=%v, want true from the well-formed redacted member", fmtBoolPtr(p))
+	}
+}
+
+func TestClient_LookupDomain_MinimalBody(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		fmt.Fprint(w, `{"objectClassName":"domain","ldhName":"EXAMPLE.COM"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+	})
+	ev := c.LookupDomain(context.Background(), "example.com")
+	if ev.Status != "taken" || ev.Confidence != "high" || ev.Err != nil {
+		t.Fatalf("status=%q confidence=%q err=%v, want high-confidence taken", ev.Status, ev.Confidence, ev.Err)
+	}
+	if ev.DomainStatus != nil || ev.Privacy != nil || ev.Variants != nil {
+		t.Fatalf("ev=%+v, want no enrichment from a minimal body", ev)
+	}
+}
+
+func TestParseDomain_Variants(t *testing.T) {
+	t.Parallel()
+
+	info := parseDomain([]byte(`{"ldhName":"xn--fo-fka.example","variants":[
+		{"relation":["registered","conjoined"],"variantNames":[{"ldhName":"xn--fo-cka.example","unicodeName":"fõo.example"}]},
+		{"relation":["unregistered","registration restricted"],"variantNames":[{"ldhName":"xn--fo-8ja.example"}]}
+	]}`))
+	want := "fõo.example (registered, conjoined)|xn--fo-8ja.example (unregistered, registration restricted)"
+	if got := strings.Join(info.variants(), "|"); got != want {
+		t.Fatalf("variants()=%q, want %q", got, want)
+	}
+
+	if got := parseDomain([]byte(`{"ldhName":"example.com"}`)).variants(); got != nil {
+		t.Fatalf("variants()=%v, want nil without variants", got)
+	}
+}
+
+func TestParseDomain_Privacy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want *bool
+	}{
+		{name: "rfc9537 registrant", body: `{"redacted":[{"name":{"type":"Registrant Name"},"method":"removal"}]}`, want: boolPtr(true)},
+		{name: "redacted other", body: `{"redacted":[{"name":{"type":"Tech Email"}}]}`, want: boolPtr(false)},
+		{name: "registrant remark", body: `{"entities":[{"roles":["registrant"],"remarks":[{"title":"REDACTED FOR PRIVACY"}]}]}`, want: boolPtr(true)},
+		{name: "top-level remark", body: `{"remarks":[{"description":["Registered through a privacy proxy service."]}]}`, want: boolPtr(true)},
+		{name: "no signal", body: `{"status":["active"]}`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDomain([]byte(tt.body)).privacy()
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("privacy()=%v, want %v", fmtBoolPtr(got), fmtBoolPtr(tt.want))
+			}
+		})
+	}
+}
+
+func fmtBoolPtr(b *bool) string {
+	if b == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*b)
+}
+
+func TestClient_LookupAlternate_SkipsUsedService(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/a/","%s/b/"]],[["net"],["%s/a/"]]]}`, srv.URL, srv.URL, srv.URL)
+		case "/b/domain/example.com":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+	})
+
+	first := c.LookupDomain(context.Background(), "example.com")
+	if first.Status != "available" {
+		t.Fatalf("first status=%q (%v), want available", first.Status, first.Err)
+	}
+	ev := c.LookupAlternate(context.Background(), "example.com", first.URL)
+	if ev.Status != "taken" || ev.URL != srv.URL+"/b/domain/example.com" {
+		t.Fatalf("alternate status=%q url=%q, want taken from /b/", ev.Status, ev.URL)
+	}
+
+	ev = c.LookupAlternate(context.Background(), "example.net", srv.URL+"/a/domain/example.net")
+	if ev.Status != "unknown" || ev.Reason != "no alternate rdap service" {
+		t.Fatalf("single-service status=%q reason=%q, want unknown/no alternate", ev.Status, ev.Reason)
+	}
+}
+
+// refuseFirst fails the first domain request as a refused connection.
+type refuseFirst struct {
+	next    http.RoundTripper
+	refused bool
+}
+
+func (rt *refuseFirst) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Path, "/domain/") && !rt.refused {
+		rt.refused = true
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+	return rt.next.RoundTrip(req)
+}
+
+func TestClient_LookupDomain_RetriesConnectionErrors(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	newClient := func(retries int) *Client {
+		return NewClient(Options{
+			BootstrapURL: srv.URL + "/dns.json",
+			CacheDir:     t.TempDir(),
+			HTTPClient:   &http.Client{Transport: &refuseFirst{next: srv.Client().Transport}},
+			Retries:      retries,
+		})
+	}
+
+	ev := newClient(0).LookupDomain(context.Background(), "example.com")
+	if ev.Status != "available" || ev.Attempts != 2 {
+		t.Fatalf("status=%q attempts=%d (%v), want available after 2 attempts", ev.Status, ev.Attempts, ev.Err)
+	}
+
+	ev = newClient(-1).LookupDomain(context.Background(), "example.com")
+	if ev.Status != "unknown" || ev.Attempts != 1 {
+		t.Fatalf("status=%q attempts=%d, want unknown after 1 attempt with retries disabled", ev.Status, ev.Attempts)
+	}
+}
+
+func TestClient_Bootstrap_SharedAcrossClients(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `{"services":[[["com"],["https://rdap.example/"]]]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	opts := Options{BootstrapURL: srv.URL + "/dns.json", CacheDir: t.TempDir(), HTTPClient: srv.Client()}
+	clients := []*Client{NewClient(opts), NewClient(opts)}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := clients[i%2].Prefetch(context.Background()); err != nil {
+				t.Errorf("Prefetch: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("bootstrap fetched %d times, want 1", n)
+	}
+
+	// A caller that gives up doesn't get stuck behind the shared load.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewClient(Options{BootstrapURL: srv.URL + "/other.json", CacheDir: t.TempDir(), HTTPClient: srv.Client()}).getBootstrap(ctx); err == nil {
+		t.Fatal("getBootstrap with canceled ctx: want error")
+	}
+}
+
+func TestClient_LookupDomain_BytesAndLatency(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"objectClassName":"domain","ldhName":"taken.com","status":["active"]}`
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{BootstrapURL: srv.URL + "/dns.json", CacheDir: t.TempDir(), HTTPClient: srv.Client()})
+
+	ev := c.LookupDomain(context.Background(), "taken.com")
+	if ev.BytesRead != len(body) {
+		t.Fatalf("BytesRead=%d, want %d", ev.BytesRead, len(body))
+	}
+	if ev.ServerLatencyMs < 20 {
+		t.Fatalf("ServerLatencyMs=%d, want >= 20", ev.ServerLatencyMs)
+	}
+}
+
+func TestDomainURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		base, domain, want string
+	}{
+		{"https://rdap.example/", "example.com", "https://rdap.example/domain/example.com"},
+		{"https://rdap.example", "example.com", "https://rdap.example/domain/example.com"},
+		{"https://rdap.example/v1//", "example.com", "https://rdap.example/v1/domain/example.com"},
+		{"https://rdap.example/rdap/", "EXAMPLE.COM", "https://rdap.example/rdap/domain/example.com"},
+		{"https://rdap.example/", "example.com.", "https://rdap.example/domain/example.com"},
+		{"https://rdap.example/", "xn--bcher-kva.de", "https://rdap.example/domain/xn--bcher-kva.de"},
+		{"https://rdap.example/", "XN--P1AI.xn--p1ai", "https://rdap.example/domain/xn--p1ai.xn--p1ai"},
+		{"https://rdap.example/domain/", "example.com", "https://rdap.example/domain/example.com"},
+		{"https://rdap.example/rdap?key=1", "example.com", "https://rdap.example/rdap/domain/example.com?key=1"},
+	} {
+		if got := domainURL(tc.base, tc.domain); got != tc.want {
+			t.Fatalf("domainURL(%q, %q)=%q, want %q", tc.base, tc.domain, got, tc.want)
+		}
+	}
+}
+
+func TestClient_LookupDomain_Maintenance(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			fmt.Fprintf(w, `{"services":[[["com"],["%s/rdap/"]]]}`, srv.URL)
+		case "/rdap/domain/down.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<html>The registry is currently undergoing maintenance.</html>")
+		case "/rdap/domain/page.com":
+			fmt.Fprint(w, "<html>Scheduled maintenance, back soon.</html>")
+		case "/rdap/domain/busy.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{
+		BootstrapURL: srv.URL + "/dns.json",
+		CacheDir:     t.TempDir(),
+		HTTPClient:   srv.Client(),
+	})
+
+	for _, d := range []string{"down.com", "page.com"} {
+		ev := c.LookupDomain(context.Background(), d)
+		if ev.Status != "unknown" || ev.Reason != "registry maintenance" || !errors.Is(ev.Err, ErrMaintenance) {
+			t.Fatalf("%s: status=%q reason=%q err=%v, want unknown registry maintenance", d, ev.Status, ev.Reason, ev.Err)
+		}
+	}
+	if ev := c.LookupDomain(context.Background(), "busy.com"); ev.Status != "unknown" || errors.Is(ev.Err, ErrMaintenance) {
+		t.Fatalf("busy.com: status=%q err=%v, want plain unknown", ev.Status, ev.Err)
+	}
+}
@@ -0,0 +1,129 @@
+package registrar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache keeps successful DomainCheck results on disk, keyed by provider and
+// domain, so re-runs within the TTL skip the provider's API. A nil *Cache
+// never hits and never stores.
+type Cache struct {
+	path string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	dirty   bool
+
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	Check     DomainCheck `json:"check"`
+	FetchedAt time.Time   `json:"fetched_at"`
+}
+
+// NewCache loads the registrar cache from dir (default: the user cache dir).
+// It returns nil (disabled) when ttl <= 0. A missing or unreadable file
+// starts an empty cache.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		return nil
+	}
+	if dir == "" {
+		if d, err := os.UserCacheDir(); err == nil && d != "" {
+			dir = filepath.Join(d, "dothuntcli")
+		}
+	}
+	c := &Cache{ttl: ttl, entries: make(map[string]cacheEntry), now: time.Now}
+	if dir == "" {
+		return c
+	}
+	c.path = filepath.Join(dir, "registrar-cache.json")
+	if b, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(b, &c.entries)
+		if c.entries == nil {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	return c
+}
+
+// Get returns the cached check for domain at provider if it is younger than
+// the TTL.
+func (c *Cache) Get(provider, domain string) (DomainCheck, bool) {
+	if c == nil {
+		return DomainCheck{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[cacheKey(provider, domain)]
+	if !ok || c.now().Sub(e.FetchedAt) > c.ttl {
+		return DomainCheck{}, false
+	}
+	return e.Check, true
+}
+
+// Put records a successful check. Rate limit info describes the quota at
+// fetch time, so it is not cached.
+func (c *Cache) Put(provider, domain string, dc DomainCheck) {
+	if c == nil {
+		return
+	}
+	dc.Limits = nil
+	c.mu.Lock()
+	c.entries[cacheKey(provider, domain)] = cacheEntry{Check: dc, FetchedAt: c.now().UTC()}
+	c.dirty = true
+	c.mu.Unlock()
+}
+
+// Save writes the cache back to disk, dropping expired entries. It is a
+// no-op when nothing was added.
+func (c *Cache) Save() error {
+	if c == nil || c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	now := c.now()
+	for k, e := range c.entries {
+		if now.Sub(e.FetchedAt) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	b, err := json.Marshal(c.entries)
+	c.dirty = false
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "registrar-cache-*.json")
+	if err != nil {
+		return err
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		if werr != nil {
+			return werr
+		}
+		return cerr
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+func cacheKey(provider, domain string) string {
+	return provider + "/" + domain
+}
@@ -0,0 +1,54 @@
+package registrar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_RoundTripAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewCache(dir, time.Hour)
+	c.now = func() time.Time { return now }
+	c.Put("porkbun", "example.com", DomainCheck{Buyable: true, Price: "9.99", Limits: &Limits{Limit: 60}})
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded := NewCache(dir, time.Hour)
+	reloaded.now = func() time.Time { return now.Add(30 * time.Minute) }
+	dc, ok := reloaded.Get("porkbun", "example.com")
+	if !ok || !dc.Buyable || dc.Price != "9.99" {
+		t.Fatalf("Get=%+v, %v; want cached buyable 9.99", dc, ok)
+	}
+	if dc.Limits != nil {
+		t.Fatalf("Limits=%+v, want nil (not cached)", dc.Limits)
+	}
+	if _, ok := reloaded.Get("namecom", "example.com"); ok {
+		t.Fatalf("Get for another provider hit, want miss")
+	}
+
+	reloaded.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, ok := reloaded.Get("porkbun", "example.com"); ok {
+		t.Fatalf("Get after TTL hit, want miss")
+	}
+}
+
+func TestCache_NilIsDisabled(t *testing.T) {
+	t.Parallel()
+
+	c := NewCache(t.TempDir(), 0)
+	if c != nil {
+		t.Fatalf("NewCache(ttl=0)=%v, want nil", c)
+	}
+	c.Put("porkbun", "example.com", DomainCheck{})
+	if _, ok := c.Get("porkbun", "example.com"); ok {
+		t.Fatalf("nil cache hit")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
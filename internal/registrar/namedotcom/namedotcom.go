@@ -0,0 +1,232 @@
+package namedotcom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+	"github.com/benithors/dothuntcli/internal/registrar"
+)
+
+const defaultBaseURL = "https://api.name.com"
+
+// MaxBatchSize is the most domains Name.com accepts per checkAvailability call.
+const MaxBatchSize = 50
+
+type Options struct {
+	Username string
+	Token    string
+	BaseURL  string
+	Timeout  time.Duration
+
+	// Client-side pacing to reduce the chance of hitting provider limits.
+	MinDelay      time.Duration
+	MaxConcurrent int
+	UserAgent     string
+
+	// HTTPClient, if set, is used instead of a client built from Timeout
+	// (e.g. one dialing through a custom resolver).
+	HTTPClient *http.Client
+
+	// Clock drives the MinDelay pacing (default clock.Real).
+	Clock clock.Clock
+}
+
+type Client struct {
+	opts Options
+	http *http.Client
+
+	sem chan struct{}
+
+	mu            sync.Mutex
+	nextRequestAt time.Time
+}
+
+func NewClient(opts Options) (*Client, error) {
+	opts.Username = strings.TrimSpace(opts.Username)
+	opts.Token = strings.TrimSpace(opts.Token)
+	if opts.Username == "" || opts.Token == "" {
+		return nil, fmt.Errorf("namecom: missing credentials (set NAMECOM_USERNAME and NAMECOM_TOKEN)")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = defaultBaseURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 8 * time.Second
+	}
+	if opts.MinDelay <= 0 {
+		opts.MinDelay = 350 * time.Millisecond
+	}
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 2
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "dothuntcli/registrar-namecom"
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real{}
+	}
+
+	httpc := opts.HTTPClient
+	if httpc == nil {
+		httpc = &http.Client{Timeout: opts.Timeout}
+	}
+
+	return &Client{
+		opts: opts,
+		http: httpc,
+		sem:  make(chan struct{}, opts.MaxConcurrent),
+	}, nil
+}
+
+func (c *Client) Name() string { return "namecom" }
+
+func (c *Client) CheckDomain(ctx context.Context, domain string) (registrar.DomainCheck, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return registrar.DomainCheck{}, fmt.Errorf("namecom: empty domain")
+	}
+	checks, err := c.CheckDomains(ctx, []string{domain})
+	if err != nil {
+		return registrar.DomainCheck{}, err
+	}
+	dc, ok := checks[domain]
+	if !ok {
+		return registrar.DomainCheck{}, fmt.Errorf("namecom: no result for %s", domain)
+	}
+	return dc, nil
+}
+
+// MaxBatchSize implements registrar.BulkChecker.
+func (c *Client) MaxBatchSize() int { return MaxBatchSize }
+
+// CheckDomains checks up to MaxBatchSize domains in one request. Domains
+// missing from the response are omitted from the returned map.
+func (c *Client) CheckDomains(ctx context.Context, domains []string) (map[string]registrar.DomainCheck, error) {
+	if len(domains) == 0 {
+		return map[string]registrar.DomainCheck{}, nil
+	}
+	if len(domains) > MaxBatchSize {
+		return nil, fmt.Errorf("namecom: %d domains exceeds the batch limit of %d", len(domains), MaxBatchSize)
+	}
+
+	// Limit in-flight requests.
+	select {
+	case c.sem <- struct{}{}:
+		defer func() { <-c.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err := c.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(checkAvailabilityRequest{DomainNames: domains})
+	if err != nil {
+		return nil, err
+	}
+
+	u := strings.TrimRight(c.opts.BaseURL, "/") + "/v4/domains:checkAvailability"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.opts.Username, c.opts.Token)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("user-agent", c.opts.UserAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("namecom: http %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return nil, fmt.Errorf("namecom: http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	var decoded checkAvailabilityResponse
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, fmt.Errorf("namecom: decode error: %w", err)
+	}
+
+	out := make(map[string]registrar.DomainCheck, len(decoded.Results))
+	for _, r := range decoded.Results {
+		name := strings.ToLower(strings.TrimSpace(r.DomainName))
+		if name == "" {
+			continue
+		}
+		dc := registrar.DomainCheck{
+			Buyable: r.Purchasable,
+			Premium: r.Premium,
+		}
+		if r.Purchasable {
+			dc.Price = formatPrice(r.PurchasePrice)
+			dc.RenewalPrice = formatPrice(r.RenewalPrice)
+			dc.Currency = "USD"
+			dc.MinDuration = 1
+		}
+		out[name] = dc
+	}
+	return out, nil
+}
+
+func (c *Client) throttle(ctx context.Context) error {
+	c.mu.Lock()
+	now := c.opts.Clock.Now()
+	scheduled := now
+	if scheduled.Before(c.nextRequestAt) {
+		scheduled = c.nextRequestAt
+	}
+	c.nextRequestAt = scheduled.Add(c.opts.MinDelay)
+	c.mu.Unlock()
+
+	return c.opts.Clock.Sleep(ctx, scheduled.Sub(now))
+}
+
+type checkAvailabilityRequest struct {
+	DomainNames []string `json:"domainNames"`
+}
+
+type checkAvailabilityResponse struct {
+	Results []struct {
+		DomainName    string  `json:"domainName"`
+		SLD           string  `json:"sld"`
+		TLD           string  `json:"tld"`
+		Purchasable   bool    `json:"purchasable"`
+		Premium       bool    `json:"premium"`
+		PurchasePrice float64 `json:"purchasePrice"`
+		PurchaseType  string  `json:"purchaseType"`
+		RenewalPrice  float64 `json:"renewalPrice"`
+	} `json:"results"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+func formatPrice(p float64) string {
+	if p <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
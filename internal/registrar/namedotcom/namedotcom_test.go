@@ -0,0 +1,115 @@
+package namedotcom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_CheckDomains_Success(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("method=%q, want POST", r.Method)
+		}
+		if r.URL.Path != "/v4/domains:checkAvailability" {
+			t.Fatalf("path=%q, want /v4/domains:checkAvailability", r.URL.Path)
+		}
+		user, token, ok := r.BasicAuth()
+		if !ok || user != "u" || token != "tok" {
+			t.Fatalf("basic auth=%q/%q (ok=%v), want u/tok", user, token, ok)
+		}
+
+		var body checkAvailabilityRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if len(body.DomainNames) != 2 {
+			t.Fatalf("domainNames=%v, want 2 domains", body.DomainNames)
+		}
+
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"results": [
+				{
+					"domainName": "free-example.com",
+					"sld": "free-example",
+					"tld": "com",
+					"purchasable": true,
+					"premium": false,
+					"purchasePrice": 12.99,
+					"purchaseType": "registration",
+					"renewalPrice": 14.99
+				},
+				{
+					"domainName": "example.com",
+					"sld": "example",
+					"tld": "com"
+				}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{
+		Username:      "u",
+		Token:         "tok",
+		BaseURL:       srv.URL,
+		Timeout:       2 * time.Second,
+		MinDelay:      1 * time.Nanosecond,
+		MaxConcurrent: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	got, err := c.CheckDomains(context.Background(), []string{"free-example.com", "example.com"})
+	if err != nil {
+		t.Fatalf("CheckDomains: %v", err)
+	}
+
+	free := got["free-example.com"]
+	if !free.Buyable || free.Premium {
+		t.Fatalf("free-example.com=%#v, want buyable non-premium", free)
+	}
+	if free.Price != "12.99" || free.RegularPrice != "" || free.RenewalPrice != "14.99" || free.Currency != "USD" {
+		t.Fatalf("free-example.com prices=%#v, want 12.99 renewing at 14.99 USD and no regular price", free)
+	}
+
+	taken, ok := got["example.com"]
+	if !ok || taken.Buyable {
+		t.Fatalf("example.com=%#v (ok=%v), want not buyable", taken, ok)
+	}
+}
+
+func TestClient_CheckDomain_ErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Unauthenticated"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{
+		Username:      "u",
+		Token:         "bad",
+		BaseURL:       srv.URL,
+		MinDelay:      1 * time.Nanosecond,
+		MaxConcurrent: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = c.CheckDomain(context.Background(), "example.com")
+	if err == nil || !strings.Contains(err.Error(), "Unauthenticated") {
+		t.Fatalf("err=%v, want message", err)
+	}
+}
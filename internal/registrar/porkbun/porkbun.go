@@ -13,11 +13,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benithors/dothuntcli/internal/clock"
 	"github.com/benithors/dothuntcli/internal/registrar"
 )
 
 const defaultBaseURL = "https://api.porkbun.com/api/json/v3"
 
+// The pricing table covers hundreds of TLDs, well above a single check
+// response, so allow a larger body.
+const maxResponseBytes = 4 << 20
+
 type Options struct {
 	APIKey       string
 	SecretAPIKey string
@@ -28,6 +33,13 @@ type Options struct {
 	MinDelay      time.Duration
 	MaxConcurrent int
 	UserAgent     string
+
+	// HTTPClient, if set, is used instead of a client built from Timeout
+	// (e.g. one dialing through a custom resolver).
+	HTTPClient *http.Client
+
+	// Clock drives the MinDelay pacing (default clock.Real).
+	Clock clock.Clock
 }
 
 type Client struct {
@@ -39,6 +51,12 @@ type Client struct {
 	mu              sync.Mutex
 	nextRequestAt   time.Time
 	dynamicMinDelay time.Duration
+	// dynamicSetAt is when dynamicMinDelay last rose or decayed; it decays
+	// at most once per rate-limit window.
+	dynamicSetAt time.Time
+
+	pricingMu sync.Mutex
+	pricing   map[string]registrar.Price
 }
 
 func NewClient(opts Options) (*Client, error) {
@@ -62,10 +80,18 @@ func NewClient(opts Options) (*Client, error) {
 	if opts.UserAgent == "" {
 		opts.UserAgent = "dothuntcli/registrar-porkbun"
 	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real{}
+	}
+
+	httpc := opts.HTTPClient
+	if httpc == nil {
+		httpc = &http.Client{Timeout: opts.Timeout}
+	}
 
 	return &Client{
 		opts: opts,
-		http: &http.Client{Timeout: opts.Timeout},
+		http: httpc,
 		sem:  make(chan struct{}, opts.MaxConcurrent),
 	}, nil
 }
@@ -78,30 +104,107 @@ func (c *Client) CheckDomain(ctx context.Context, domain string) (registrar.Doma
 		return registrar.DomainCheck{}, fmt.Errorf("porkbun: empty domain")
 	}
 
+	b, err := c.post(ctx, "/domain/checkDomain/"+url.PathEscape(domain))
+	if err != nil {
+		return registrar.DomainCheck{}, err
+	}
+
+	var decoded checkDomainResponse
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return registrar.DomainCheck{}, fmt.Errorf("porkbun: decode error: %w", err)
+	}
+	if strings.ToUpper(decoded.Status) != "SUCCESS" {
+		msg := strings.TrimSpace(decoded.Message)
+		if msg == "" {
+			msg = "unknown error"
+		}
+		return registrar.DomainCheck{}, fmt.Errorf("porkbun: %s", msg)
+	}
+
+	check := registrar.DomainCheck{
+		Buyable:        yesNo(decoded.Response.Avail),
+		Premium:        yesNo(decoded.Response.Premium),
+		Price:          strings.TrimSpace(string(decoded.Response.Price)),
+		RegularPrice:   strings.TrimSpace(string(decoded.Response.RegularPrice)),
+		RenewalPrice:   strings.TrimSpace(string(decoded.Response.Additional.Renewal.Price)),
+		MinDuration:    int(decoded.Response.MinDuration),
+		FirstYearPromo: yesNo(decoded.Response.FirstYearPromo),
+	}
+
+	limits := parseLimits(decoded.Limits)
+	if limits != nil {
+		check.Limits = limits
+		c.updateDynamicDelay(*limits)
+	}
+
+	return check, nil
+}
+
+// ListTLDPricing returns Porkbun's registration/renewal/transfer prices for
+// every TLD it sells. The result is cached for the lifetime of the client.
+func (c *Client) ListTLDPricing(ctx context.Context) (map[string]registrar.Price, error) {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+	if c.pricing != nil {
+		return c.pricing, nil
+	}
+
+	b, err := c.post(ctx, "/pricing/get")
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded pricingResponse
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, fmt.Errorf("porkbun: decode error: %w", err)
+	}
+	if strings.ToUpper(decoded.Status) != "SUCCESS" {
+		msg := strings.TrimSpace(decoded.Message)
+		if msg == "" {
+			msg = "unknown error"
+		}
+		return nil, fmt.Errorf("porkbun: %s", msg)
+	}
+
+	out := make(map[string]registrar.Price, len(decoded.Pricing))
+	for tld, p := range decoded.Pricing {
+		out[strings.ToLower(strings.TrimSpace(tld))] = registrar.Price{
+			Registration: strings.TrimSpace(string(p.Registration)),
+			Renewal:      strings.TrimSpace(string(p.Renewal)),
+			Transfer:     strings.TrimSpace(string(p.Transfer)),
+		}
+	}
+	c.pricing = out
+	return out, nil
+}
+
+// post sends an authenticated, throttled POST to path and returns the body of
+// a 200 response.
+func (c *Client) post(ctx context.Context, path string) ([]byte, error) {
 	// Limit in-flight requests.
 	select {
 	case c.sem <- struct{}{}:
 		defer func() { <-c.sem }()
 	case <-ctx.Done():
-		return registrar.DomainCheck{}, ctx.Err()
+		return nil, ctx.Err()
 	}
 
 	if err := c.throttle(ctx); err != nil {
-		return registrar.DomainCheck{}, err
+		return nil, err
 	}
 
-	u := strings.TrimRight(c.opts.BaseURL, "/") + "/domain/checkDomain/" + url.PathEscape(domain)
+	u := strings.TrimRight(c.opts.BaseURL, "/") + path
 	body, err := json.Marshal(map[string]string{
 		"apikey":       c.opts.APIKey,
 		"secretapikey": c.opts.SecretAPIKey,
 	})
 	if err != nil {
-		return registrar.DomainCheck{}, err
+		return nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
 	if err != nil {
-		return registrar.DomainCheck{}, err
+		return nil, err
 	}
 	req.Header.Set("content-type", "application/json")
 	req.Header.Set("accept", "application/json")
@@ -109,46 +212,18 @@ func (c *Client) CheckDomain(ctx context.Context, domain string) (registrar.Doma
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return registrar.DomainCheck{}, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
 	if err != nil {
-		return registrar.DomainCheck{}, err
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return registrar.DomainCheck{}, fmt.Errorf("porkbun: http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return nil, fmt.Errorf("porkbun: http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
 	}
-
-	var decoded checkDomainResponse
-	if err := json.Unmarshal(b, &decoded); err != nil {
-		return registrar.DomainCheck{}, fmt.Errorf("porkbun: decode error: %w", err)
-	}
-	if strings.ToUpper(decoded.Status) != "SUCCESS" {
-		msg := strings.TrimSpace(decoded.Message)
-		if msg == "" {
-			msg = "unknown error"
-		}
-		return registrar.DomainCheck{}, fmt.Errorf("porkbun: %s", msg)
-	}
-
-	check := registrar.DomainCheck{
-		Buyable:        yesNo(decoded.Response.Avail),
-		Premium:        yesNo(decoded.Response.Premium),
-		Price:          strings.TrimSpace(decoded.Response.Price),
-		RegularPrice:   strings.TrimSpace(decoded.Response.RegularPrice),
-		MinDuration:    decoded.Response.MinDuration,
-		FirstYearPromo: yesNo(decoded.Response.FirstYearPromo),
-	}
-
-	limits := parseLimits(decoded.Limits)
-	if limits != nil {
-		check.Limits = limits
-		c.updateDynamicDelay(*limits)
-	}
-
-	return check, nil
+	return b, nil
 }
 
 func (c *Client) throttle(ctx context.Context) error {
@@ -158,7 +233,7 @@ func (c *Client) throttle(ctx context.Context) error {
 		minDelay = c.dynamicMinDelay
 	}
 
-	now := time.Now()
+	now := c.opts.Clock.Now()
 	scheduled := now
 	if scheduled.Before(c.nextRequestAt) {
 		scheduled = c.nextRequestAt
@@ -166,25 +241,20 @@ func (c *Client) throttle(ctx context.Context) error {
 	c.nextRequestAt = scheduled.Add(minDelay)
 	c.mu.Unlock()
 
-	wait := time.Until(scheduled)
-	if wait <= 0 {
-		return nil
-	}
-	t := time.NewTimer(wait)
-	defer t.Stop()
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-t.C:
-		return nil
-	}
+	return c.opts.Clock.Sleep(ctx, scheduled.Sub(now))
 }
 
+// updateDynamicDelay paces requests to the budget a response advertises
+// (TTL / limit). The first budget seen is adopted as is; after that, a
+// response with more than half the quota used raises the delay, while one
+// with ample quota left halves the excess over MinDelay, at most once per
+// window, so a single tight response doesn't slow the rest of a long run.
 func (c *Client) updateDynamicDelay(l registrar.Limits) {
 	if l.TTLSeconds <= 0 || l.Limit <= 0 {
 		return
 	}
-	per := time.Duration(l.TTLSeconds) * time.Second / time.Duration(l.Limit)
+	window := time.Duration(l.TTLSeconds) * time.Second
+	per := window / time.Duration(l.Limit)
 	if per <= 0 {
 		return
 	}
@@ -193,28 +263,55 @@ func (c *Client) updateDynamicDelay(l registrar.Limits) {
 	if per > 5*time.Second {
 		per = 5 * time.Second
 	}
+	ample := l.Used*2 <= l.Limit
 
 	c.mu.Lock()
-	if per > c.dynamicMinDelay {
+	defer c.mu.Unlock()
+	now := c.opts.Clock.Now()
+	switch {
+	case per > c.dynamicMinDelay && (c.dynamicSetAt.IsZero() || !ample):
 		c.dynamicMinDelay = per
+		c.dynamicSetAt = now
+	case ample && c.dynamicMinDelay > c.opts.MinDelay && now.Sub(c.dynamicSetAt) >= window:
+		c.dynamicMinDelay = c.opts.MinDelay + (c.dynamicMinDelay-c.opts.MinDelay)/2
+		if c.dynamicMinDelay-c.opts.MinDelay < time.Millisecond {
+			c.dynamicMinDelay = c.opts.MinDelay
+		}
+		c.dynamicSetAt = now
 	}
-	c.mu.Unlock()
 }
 
 type checkDomainResponse struct {
 	Status   string `json:"status"`
 	Message  string `json:"message,omitempty"`
 	Response struct {
-		Avail          string `json:"avail"`
-		Price          string `json:"price"`
-		RegularPrice   string `json:"regularPrice"`
-		Premium        string `json:"premium"`
-		MinDuration    int    `json:"minDuration"`
-		FirstYearPromo string `json:"firstYearPromo"`
+		Avail          string     `json:"avail"`
+		Price          jsonString `json:"price"`
+		RegularPrice   jsonString `json:"regularPrice"`
+		Premium        string     `json:"premium"`
+		MinDuration    jsonInt    `json:"minDuration"`
+		FirstYearPromo string     `json:"firstYearPromo"`
+		// Additional holds the follow-on prices; renewal is what the name
+		// costs each year after the first.
+		Additional struct {
+			Renewal struct {
+				Price jsonString `json:"price"`
+			} `json:"renewal"`
+		} `json:"additional"`
 	} `json:"response"`
 	Limits apiLimits `json:"limits"`
 }
 
+type pricingResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Pricing map[string]struct {
+		Registration jsonString `json:"registration"`
+		Renewal      jsonString `json:"renewal"`
+		Transfer     jsonString `json:"transfer"`
+	} `json:"pricing"`
+}
+
 type apiLimits struct {
 	TTL             jsonInt `json:"TTL"`
 	Limit           jsonInt `json:"limit"`
@@ -251,6 +348,34 @@ func (i *jsonInt) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// jsonString accepts either a JSON string or a JSON number (kept verbatim,
+// e.g. 10.29 -> "10.29").
+type jsonString string
+
+func (s *jsonString) UnmarshalJSON(b []byte) error {
+	raw := strings.TrimSpace(string(b))
+	if raw == "" || raw == "null" {
+		*s = ""
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		var v string
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		*s = jsonString(v)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*s = jsonString(n.String())
+	return nil
+}
+
 func parseLimits(l apiLimits) *registrar.Limits {
 	ttl := int(l.TTL)
 	limit := int(l.Limit)
@@ -3,11 +3,15 @@ package porkbun
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+	"github.com/benithors/dothuntcli/internal/registrar"
 )
 
 func TestClient_CheckDomain_Success(t *testing.T) {
@@ -38,7 +42,8 @@ func TestClient_CheckDomain_Success(t *testing.T) {
 				"regularPrice":"10.29",
 				"premium":"no",
 				"minDuration":1,
-				"firstYearPromo":"no"
+				"firstYearPromo":"no",
+				"additional":{"renewal":{"type":"renewal","price":"11.06","regularPrice":"11.06"}}
 			},
 			"limits":{"TTL":"10","limit":"100","used":1,"naturalLanguage":"example"}
 		}`))
@@ -70,6 +75,9 @@ func TestClient_CheckDomain_Success(t *testing.T) {
 	if got.Price != "10.29" {
 		t.Fatalf("Price=%q, want 10.29", got.Price)
 	}
+	if got.RenewalPrice != "11.06" {
+		t.Fatalf("RenewalPrice=%q, want 11.06", got.RenewalPrice)
+	}
 	if got.MinDuration != 1 {
 		t.Fatalf("MinDuration=%d, want 1", got.MinDuration)
 	}
@@ -117,3 +125,139 @@ func TestClient_CheckDomain_ErrorStatus(t *testing.T) {
 		t.Fatalf("err=%v, want message", err)
 	}
 }
+
+func TestCheckDomainResponse_AcceptsStringAndNumericPrices(t *testing.T) {
+	t.Parallel()
+
+	for _, payload := range []string{
+		`{"status":"SUCCESS","response":{"avail":"yes","price":"10.29","regularPrice":"12.50","minDuration":"1"}}`,
+		`{"status":"SUCCESS","response":{"avail":"yes","price":10.29,"regularPrice":12.50,"minDuration":1}}`,
+	} {
+		var got checkDomainResponse
+		if err := json.Unmarshal([]byte(payload), &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", payload, err)
+		}
+		if got.Response.Price != "10.29" {
+			t.Fatalf("Price=%q, want 10.29 (payload %s)", got.Response.Price, payload)
+		}
+		if got.Response.RegularPrice != "12.50" {
+			t.Fatalf("RegularPrice=%q, want 12.50 (payload %s)", got.Response.RegularPrice, payload)
+		}
+		if got.Response.MinDuration != 1 {
+			t.Fatalf("MinDuration=%d, want 1 (payload %s)", got.Response.MinDuration, payload)
+		}
+	}
+}
+
+func TestClient_ListTLDPricing_Cached(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pricing/get" {
+			t.Fatalf("path=%q, want /pricing/get", r.URL.Path)
+		}
+		calls++
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"status":"SUCCESS",
+			"pricing":{
+				"com":{"registration":"9.68","renewal":"10.37","transfer":"9.68","coupons":[]},
+				"IO":{"registration":28.12,"renewal":"41.50","transfer":"41.50"}
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Options{APIKey: "k", SecretAPIKey: "s", BaseURL: srv.URL, MinDelay: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := c.ListTLDPricing(context.Background())
+		if err != nil {
+			t.Fatalf("ListTLDPricing: %v", err)
+		}
+		if p := got["com"]; p.Registration != "9.68" || p.Renewal != "10.37" || p.Transfer != "9.68" {
+			t.Fatalf("com=%+v, want parsed", p)
+		}
+		if p := got["io"]; p.Registration != "28.12" {
+			t.Fatalf("io=%+v, want lower-cased key and numeric price", p)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d, want 1 (cached)", calls)
+	}
+}
+
+func TestClient_Throttle_DynamicDelay(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"SUCCESS","response":{"avail":"yes"},"limits":{"TTL":"10","limit":"100","used":1}}`))
+	}))
+	defer srv.Close()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := NewClient(Options{APIKey: "k", SecretAPIKey: "s", BaseURL: srv.URL, MinDelay: 50 * time.Millisecond, Clock: clk})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.CheckDomain(context.Background(), "example.com"); err != nil {
+			t.Fatalf("CheckDomain: %v", err)
+		}
+	}
+	// The second request waits MinDelay; after it, the advertised budget
+	// (100 per 10s) spaces requests 100ms apart.
+	if want := "[50ms 100ms]"; fmt.Sprint(clk.Sleeps()) != want {
+		t.Fatalf("sleeps=%v, want %s", clk.Sleeps(), want)
+	}
+}
+
+func TestClient_DynamicDelay_DecaysWithAmpleQuota(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := NewClient(Options{APIKey: "k", SecretAPIKey: "s", MinDelay: 50 * time.Millisecond, Clock: clk})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	delay := func() time.Duration {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.dynamicMinDelay
+	}
+
+	// 100 requests per 10s: 100ms spacing.
+	for i, step := range []struct {
+		advance time.Duration
+		used    int
+		want    time.Duration
+	}{
+		{0, 10, 100 * time.Millisecond},               // first budget adopted
+		{time.Second, 10, 100 * time.Millisecond},     // ample, but within the window
+		{10 * time.Second, 10, 75 * time.Millisecond}, // a window of ample quota: halve the excess
+		{10 * time.Second, 20, 62500 * time.Microsecond},
+		{time.Second, 90, 100 * time.Millisecond},      // nearly exhausted: back up
+		{10 * time.Second, 80, 100 * time.Millisecond}, // still tight: no decay
+		{10 * time.Second, 0, 75 * time.Millisecond},
+	} {
+		clk.Advance(step.advance)
+		c.updateDynamicDelay(registrar.Limits{TTLSeconds: 10, Limit: 100, Used: step.used})
+		if got := delay(); got != step.want {
+			t.Fatalf("step %d (used %d): delay=%v, want %v", i, step.used, got, step.want)
+		}
+	}
+
+	for range 10 {
+		clk.Advance(10 * time.Second)
+		c.updateDynamicDelay(registrar.Limits{TTLSeconds: 10, Limit: 100, Used: 1})
+	}
+	if got := delay(); got != 50*time.Millisecond {
+		t.Fatalf("delay=%v, want MinDelay after a quiet stretch", got)
+	}
+}
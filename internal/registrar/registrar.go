@@ -12,6 +12,7 @@ type DomainCheck struct {
 	Premium        bool
 	Price          string // price for the minimum duration (usually 1 year)
 	RegularPrice   string // non-promo price if available
+	RenewalPrice   string // yearly renewal price if available (may differ from both)
 	Currency       string // e.g. USD
 	MinDuration    int    // years
 	FirstYearPromo bool
@@ -26,3 +27,25 @@ type Limits struct {
 	Used            int    `json:"used,omitempty"`
 	NaturalLanguage string `json:"natural_language,omitempty"`
 }
+
+// BulkChecker is implemented by providers that can check several domains in
+// one request. Domains missing from the returned map had no answer.
+type BulkChecker interface {
+	CheckDomains(ctx context.Context, domains []string) (map[string]DomainCheck, error)
+	// MaxBatchSize is the most domains the provider accepts per request, or
+	// 0 when it publishes no limit.
+	MaxBatchSize() int
+}
+
+// Price is a registrar's list price for a TLD.
+type Price struct {
+	Registration string `json:"registration,omitempty"`
+	Renewal      string `json:"renewal,omitempty"`
+	Transfer     string `json:"transfer,omitempty"`
+}
+
+// PriceLister is implemented by providers that publish TLD-wide pricing.
+// Keys are lower-case ASCII TLDs.
+type PriceLister interface {
+	ListTLDPricing(ctx context.Context) (map[string]Price, error)
+}
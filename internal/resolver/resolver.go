@@ -0,0 +1,170 @@
+// Package resolver builds a net.Resolver that sends DNS queries to a chosen
+// server instead of the system one: DNS-over-HTTPS, DNS-over-TLS or plain DNS.
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const maxMessageBytes = 64 << 10
+
+// New parses spec and returns a resolver that uses it for every query:
+//
+//	https://dns.example/dns-query   DNS-over-HTTPS (RFC 8484)
+//	tls://dns.example[:853]         DNS-over-TLS
+//	dns.example[:53], udp://...     plain DNS
+//
+// An empty spec returns nil, meaning the system resolver. httpc is used for
+// DoH (nil means a client with a 10s timeout); the DoH server's own name is
+// resolved by the system resolver, so use an IP in the URL to avoid that.
+func New(spec string, httpc *http.Client) (*net.Resolver, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	scheme, rest, ok := strings.Cut(spec, "://")
+	if !ok {
+		scheme, rest = "udp", spec
+	}
+	var dial func(ctx context.Context, network, address string) (net.Conn, error)
+	switch strings.ToLower(scheme) {
+	case "https":
+		u, err := url.Parse(spec)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid DoH URL %q", spec)
+		}
+		if httpc == nil {
+			httpc = &http.Client{Timeout: 10 * time.Second}
+		}
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, http: httpc, url: spec}, nil
+		}
+	case "tls":
+		addr, host, err := hostPort(rest, "853")
+		if err != nil {
+			return nil, err
+		}
+		d := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "tcp", addr)
+		}
+	case "udp", "tcp":
+		addr, _, err := hostPort(rest, "53")
+		if err != nil {
+			return nil, err
+		}
+		network := strings.ToLower(scheme)
+		var d net.Dialer
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, network, addr)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resolver scheme %q (use https://, tls://, udp:// or host[:port])", scheme)
+	}
+
+	return &net.Resolver{PreferGo: true, Dial: dial}, nil
+}
+
+// hostPort validates host[:port], filling in defPort.
+func hostPort(s, defPort string) (addr, host string, err error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "/")
+	if s == "" {
+		return "", "", errors.New("resolver: empty host")
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		host, port = strings.Trim(s, "[]"), defPort
+	}
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		return "", "", fmt.Errorf("resolver: invalid host %q", s)
+	}
+	return net.JoinHostPort(host, port), host, nil
+}
+
+// dohConn lets the Go resolver speak DoH: it receives queries in DNS-over-TCP
+// framing (the resolver uses that for any conn that isn't a PacketConn),
+// POSTs each one as application/dns-message and frames the answer for Read.
+type dohConn struct {
+	ctx  context.Context
+	http *http.Client
+	url  string
+
+	wbuf, rbuf bytes.Buffer
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.wbuf.Write(b)
+	for c.wbuf.Len() >= 2 {
+		n := int(binary.BigEndian.Uint16(c.wbuf.Bytes()))
+		if c.wbuf.Len() < 2+n {
+			break
+		}
+		c.wbuf.Next(2)
+		answer, err := c.exchange(c.wbuf.Next(n))
+		if err != nil {
+			return 0, err
+		}
+		var prefix [2]byte
+		binary.BigEndian.PutUint16(prefix[:], uint16(len(answer)))
+		c.rbuf.Write(prefix[:])
+		c.rbuf.Write(answer)
+	}
+	return len(b), nil
+}
+
+func (c *dohConn) exchange(query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("content-type", "application/dns-message")
+	req.Header.Set("accept", "application/dns-message")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doh: http %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxMessageBytes))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0xffff {
+		return nil, errors.New("doh: answer too large")
+	}
+	return b, nil
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.rbuf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.rbuf.Read(b)
+}
+
+func (c *dohConn) Close() error                     { return nil }
+func (c *dohConn) LocalAddr() net.Addr              { return dohAddr(c.url) }
+func (c *dohConn) RemoteAddr() net.Addr             { return dohAddr(c.url) }
+func (c *dohConn) SetDeadline(time.Time) error      { return nil }
+func (c *dohConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(time.Time) error { return nil }
+
+type dohAddr string
+
+func (a dohAddr) Network() string { return "doh" }
+func (a dohAddr) String() string  { return string(a) }
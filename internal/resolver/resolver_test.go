@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestNew_DoH(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("content-type") != "application/dns-message" {
+			http.Error(w, "bad content-type", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var q dnsmessage.Message
+		if err := q.Unpack(body); err != nil || len(q.Questions) != 1 {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: q.ID, Response: true, Authoritative: true},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			resp.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			}}
+		}
+		b, _ := resp.Pack()
+		w.Header().Set("content-type", "application/dns-message")
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	res, err := New(srv.URL+"/dns-query", srv.Client())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	addrs, err := res.LookupHost(context.Background(), "example.test.")
+	if err != nil {
+		t.Fatalf("LookupHost: %v", err)
+	}
+	if fmt.Sprint(addrs) != "[192.0.2.1]" {
+		t.Fatalf("addrs=%v, want [192.0.2.1]", addrs)
+	}
+}
+
+func TestNew_Specs(t *testing.T) {
+	t.Parallel()
+
+	if res, err := New("", nil); res != nil || err != nil {
+		t.Fatalf("New(\"\")=%v, %v; want system resolver (nil)", res, err)
+	}
+	for _, spec := range []string{"1.1.1.1", "udp://1.1.1.1:5353", "tls://dns.example", "[2001:db8::1]:53"} {
+		if _, err := New(spec, nil); err != nil {
+			t.Fatalf("New(%q): %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"ftp://dns.example", "tls://", "https://"} {
+		if _, err := New(spec, nil); err == nil {
+			t.Fatalf("New(%q): expected error", spec)
+		}
+	}
+}
@@ -3,25 +3,89 @@ package whois
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/ratelimit"
+	"golang.org/x/sync/singleflight"
 )
 
 type Options struct {
 	Timeout time.Duration
 	Verbose bool
 
+	// TLD -> WHOIS server referrals from IANA are cached on disk.
+	CacheDir string
+	CacheTTL time.Duration
+
+	// NoDiskCache keeps referrals in memory only: CacheDir is neither read
+	// nor written.
+	NoDiskCache bool
+
 	// Safety valves for WHOIS servers.
 	MaxConcurrentPerServer int
 	MinDelayPerServer      time.Duration
 	Retries                int
 	Backoff                time.Duration
+
+	// BackoffStrategy controls how the delay between retries grows from
+	// Backoff (default exponential); MaxBackoff caps it (default 2s).
+	BackoffStrategy BackoffStrategy
+	MaxBackoff      time.Duration
+
+	// MaxBodyBytes caps how much of a response is read (default 1 MiB).
+	MaxBodyBytes int64
+
+	// DialFunc, if set, replaces the default TCP dialer (useful for tests and
+	// benchmarks). Resolver and FallbackDelay then have no effect.
+	DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Resolver, if set, resolves server names for the default dialer.
+	Resolver *net.Resolver
+
+	// FallbackDelay is how long the default dialer waits on the first
+	// address family of a dual-stack server before racing the other one
+	// (Happy Eyeballs). 0 means 300ms; negative dials one family at a time.
+	FallbackDelay time.Duration
+
+	// ServerOverrides maps a TLD or public suffix (e.g. "com.au") to a WHOIS
+	// server, bypassing IANA. Servers may be "host" or "host:port"; see
+	// ServerAddr.
+	ServerOverrides map[string]string
+
+	// MemoizeWithinRun reuses the evidence for a domain already looked up by
+	// this client instead of querying again; concurrent lookups of the same
+	// domain share one query. Failed lookups are not memoized.
+	MemoizeWithinRun bool
+
+	// Limiter, if set, is waited on before every domain query attempt (not
+	// IANA referrals), keyed by the domain's TLD. Give the RDAP client the
+	// same one so both methods draw on a single per-registry budget.
+	Limiter ratelimit.Limiter
+
+	// Clock paces queries per server and times retry backoff (default
+	// clock.Real).
+	Clock clock.Clock
+
+	// RefusedCooldown is how long a server that refused RefusedThreshold
+	// connections in a row (default 2) is skipped: queries fail with
+	// ErrUnreachable without dialing. Stale IANA entries for RDAP-only TLDs
+	// often point at such servers. 0 means 10m; negative never skips.
+	RefusedCooldown  time.Duration
+	RefusedThreshold int
 }
 
 type Client struct {
@@ -30,6 +94,11 @@ type Client struct {
 	mu          sync.Mutex
 	tldToServer map[string]string
 	serverState map[string]*perServerState
+	diskCache   map[string]serverCacheEntry
+	diskLoaded  bool
+	diskWriteMu sync.Mutex
+	memo        map[string]Evidence
+	memoCalls   singleflight.Group
 }
 
 type Evidence struct {
@@ -39,12 +108,52 @@ type Evidence struct {
 	Server     string
 	Pattern    string
 	Err        error
+
+	// DomainStatus holds EPP status codes from "Domain Status:"/"Status:" lines
+	// of a record, when present.
+	DomainStatus []string
+
+	// Truncated is set when the response hit MaxBodyBytes; the classification
+	// may have missed data past the cap.
+	Truncated bool
+
+	// Attempts is how many times the domain query was sent (retries included).
+	Attempts int
+
+	// ServerChain is the referral path to Server, e.g. ["whois.iana.org",
+	// "whois.verisign-grs.com"], or just the server for an override or a
+	// suffix cache entry. An IANA referral read from cache still lists IANA.
+	ServerChain []string
+
+	// BytesRead and ServerLatencyMs describe the answering exchange: bytes
+	// received and milliseconds from connection established to body read
+	// (dialing and rate-limit waits excluded). Zero when the query failed.
+	BytesRead       int
+	ServerLatencyMs int64
 }
 
+// ErrNoServer is returned when IANA lists no WHOIS server for a TLD (common
+// for RDAP-only registries).
+var ErrNoServer = errors.New("whois server not found")
+
+// ErrMaintenance is returned (by failed lookups, in Evidence.Err) when the
+// server answers with a registry maintenance notice instead of a record.
+// It is retried like a transient network error.
+var ErrMaintenance = errors.New("registry maintenance")
+
+// ErrUnreachable is returned without dialing while a server is cooling down
+// after refusing connections (see Options.RefusedCooldown).
+var ErrUnreachable = errors.New("whois server refuses connections")
+
 type perServerState struct {
 	sem  chan struct{}
 	mu   sync.Mutex
 	next time.Time
+
+	// refused counts consecutive refused dials; the server is skipped until
+	// skipUntil once it reaches RefusedThreshold.
+	refused   int
+	skipUntil time.Time
 }
 
 func NewClient(opts Options) *Client {
@@ -66,6 +175,44 @@ func NewClient(opts Options) *Client {
 	if opts.Backoff <= 0 {
 		opts.Backoff = 250 * time.Millisecond
 	}
+	if opts.BackoffStrategy == "" {
+		opts.BackoffStrategy = BackoffExponential
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 2 * time.Second
+	}
+	if opts.DialFunc == nil {
+		opts.DialFunc = (&net.Dialer{Resolver: opts.Resolver, FallbackDelay: opts.FallbackDelay}).DialContext
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 7 * 24 * time.Hour
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real{}
+	}
+	if opts.RefusedCooldown == 0 {
+		opts.RefusedCooldown = 10 * time.Minute
+	}
+	if opts.RefusedThreshold <= 0 {
+		opts.RefusedThreshold = 2
+	}
+	if opts.NoDiskCache {
+		opts.CacheDir = ""
+	} else if opts.CacheDir == "" {
+		if d, err := os.UserCacheDir(); err == nil && d != "" {
+			opts.CacheDir = filepath.Join(d, "dothuntcli")
+		}
+	}
+	if len(opts.ServerOverrides) > 0 {
+		overrides := make(map[string]string, len(opts.ServerOverrides))
+		for k, v := range opts.ServerOverrides {
+			overrides[strings.Trim(strings.ToLower(strings.TrimSpace(k)), ".")] = strings.TrimSpace(v)
+		}
+		opts.ServerOverrides = overrides
+	}
 	return &Client{
 		opts:        opts,
 		tldToServer: make(map[string]string, 256),
@@ -73,68 +220,195 @@ func NewClient(opts Options) *Client {
 }
 
 func (c *Client) LookupDomain(ctx context.Context, domain string) Evidence {
+	if !c.opts.MemoizeWithinRun {
+		return c.lookupDomain(ctx, domain)
+	}
+
+	key := strings.ToLower(domain)
+	c.mu.Lock()
+	ev, ok := c.memo[key]
+	c.mu.Unlock()
+	if ok {
+		return ev
+	}
+
+	v, _, _ := c.memoCalls.Do(key, func() (any, error) {
+		ev := c.lookupDomain(ctx, domain)
+		if ev.Err == nil {
+			c.mu.Lock()
+			if c.memo == nil {
+				c.memo = make(map[string]Evidence)
+			}
+			c.memo[key] = ev
+			c.mu.Unlock()
+		}
+		return ev, nil
+	})
+	return v.(Evidence)
+}
+
+func (c *Client) lookupDomain(ctx context.Context, domain string) Evidence {
 	tld := lastLabel(domain)
 	if tld == "" {
 		return Evidence{Status: "unknown", Confidence: "low", Reason: "invalid domain", Err: fmt.Errorf("invalid domain")}
 	}
 
-	server, err := c.serverForTLD(ctx, tld)
+	server, chain, err := c.serverForDomain(ctx, domain, tld)
 	if err != nil {
-		return Evidence{Status: "unknown", Confidence: "low", Reason: "no whois server", Err: err}
+		return Evidence{Status: "unknown", Confidence: "low", Reason: "no whois server", ServerChain: chain, Err: err}
 	}
 
-	body, err := c.query(ctx, server, domain)
+	resp, err := c.query(ctx, server, domain, tld)
 	if err != nil {
-		return Evidence{Status: "unknown", Confidence: "low", Reason: "whois query failed", Server: server, Err: err}
+		reason := "whois query failed"
+		switch {
+		case errors.Is(err, ErrMaintenance):
+			reason = "registry maintenance"
+		case errors.Is(err, ErrUnreachable), isConnRefused(err):
+			reason = "whois unreachable"
+		}
+		return Evidence{Status: "unknown", Confidence: "low", Reason: reason, Server: server, ServerChain: chain, Attempts: resp.Attempts, Err: err}
 	}
 
-	status, pattern := classify(domain, body)
+	status, pattern := classify(domain, resp.Body)
+	var ev Evidence
 	switch status {
 	case "available":
-		return Evidence{
+		ev = Evidence{
 			Status:     "available",
 			Confidence: "medium",
 			Reason:     "whois not-found pattern",
 			Server:     server,
 			Pattern:    pattern,
 		}
-	case "taken":
-		return Evidence{
-			Status:     "taken",
+	case "reserved":
+		ev = Evidence{
+			Status:     "reserved",
 			Confidence: "medium",
-			Reason:     "whois record found",
+			Reason:     "whois reserved pattern",
 			Server:     server,
 			Pattern:    pattern,
 		}
+	case "taken":
+		ev = Evidence{
+			Status:       "taken",
+			Confidence:   "medium",
+			Reason:       "whois record found",
+			Server:       server,
+			Pattern:      pattern,
+			DomainStatus: parseStatuses(resp.Body),
+		}
 	default:
-		return Evidence{
+		ev = Evidence{
 			Status:     "unknown",
 			Confidence: "low",
 			Reason:     "whois ambiguous",
 			Server:     server,
 		}
 	}
+	ev.Attempts = resp.Attempts
+	ev.ServerChain = chain
+	ev.BytesRead = resp.BytesRead
+	ev.ServerLatencyMs = resp.Latency.Milliseconds()
+	if resp.Truncated {
+		ev.Truncated = true
+		ev.Confidence = "low"
+		ev.Reason += " (response truncated)"
+	}
+	return ev
 }
 
-func (c *Client) serverForTLD(ctx context.Context, tld string) (string, error) {
+// ServerForTLD resolves (and caches) the WHOIS server for a TLD via IANA.
+func (c *Client) ServerForTLD(ctx context.Context, tld string) (string, error) {
+	server, _, err := c.serverForTLD(ctx, tld)
+	return server, err
+}
+
+// ianaServer is where TLD referrals come from.
+const ianaServer = "whois.iana.org"
+
+// serverForDomain prefers a known server for a multi-level public suffix
+// (e.g. "com.au"). IANA only delegates top-level labels, so those longer
+// suffixes are only looked up in the caches, never queried. chain is the
+// referral path to the server (see Evidence.ServerChain).
+func (c *Client) serverForDomain(ctx context.Context, name, tld string) (server string, chain []string, err error) {
+	for _, suffix := range domain.SuffixCandidates(name) {
+		if s, ok := c.opts.ServerOverrides[suffix]; ok {
+			return s, []string{s}, nil
+		}
+		if suffix == tld {
+			break
+		}
+		if s, ok := c.cachedServer(suffix); ok {
+			return s, []string{s}, nil
+		}
+	}
+	server, viaIANA, err := c.serverForTLD(ctx, tld)
+	switch {
+	case viaIANA && server != "":
+		chain = []string{ianaServer, server}
+	case viaIANA:
+		chain = []string{ianaServer}
+	case server != "":
+		chain = []string{server}
+	}
+	return server, chain, err
+}
+
+// cachedServer returns a non-empty server for key from memory or the disk
+// cache without querying IANA.
+func (c *Client) cachedServer(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.tldToServer[key]; ok {
+		return s, s != ""
+	}
+	c.loadDiskCacheLocked()
+	if e, ok := c.diskCache[key]; ok && e.Server != "" {
+		if c.opts.CacheTTL <= 0 || time.Since(e.FetchedAt) <= c.opts.CacheTTL {
+			c.tldToServer[key] = e.Server
+			return e.Server, true
+		}
+	}
+	return "", false
+}
+
+// serverForTLD returns tld's WHOIS server; viaIANA reports that it came
+// (now or earlier, through the caches) from an IANA referral, or that IANA
+// was asked and had none.
+func (c *Client) serverForTLD(ctx context.Context, tld string) (server string, viaIANA bool, err error) {
 	tld = strings.ToLower(strings.TrimSpace(tld))
 	if tld == "" {
-		return "", fmt.Errorf("empty tld")
+		return "", false, fmt.Errorf("empty tld")
+	}
+	if s, ok := c.opts.ServerOverrides[tld]; ok {
+		return s, false, nil
 	}
 
 	c.mu.Lock()
-	if s, ok := c.tldToServer[tld]; ok && s != "" {
+	if s, ok := c.tldToServer[tld]; ok {
 		c.mu.Unlock()
-		return s, nil
+		if s == "" {
+			return "", true, fmt.Errorf("%w for tld %q", ErrNoServer, tld)
+		}
+		return s, true, nil
+	}
+	c.loadDiskCacheLocked()
+	if e, ok := c.diskCache[tld]; ok && e.Server != "" {
+		if c.opts.CacheTTL <= 0 || time.Since(e.FetchedAt) <= c.opts.CacheTTL {
+			c.tldToServer[tld] = e.Server
+			c.mu.Unlock()
+			return e.Server, true, nil
+		}
 	}
 	c.mu.Unlock()
 
-	body, err := c.query(ctx, "whois.iana.org", tld)
+	resp, err := c.query(ctx, ianaServer, tld, "")
 	if err != nil {
-		return "", err
+		return "", true, err
 	}
 
-	sc := bufio.NewScanner(strings.NewReader(body))
+	sc := bufio.NewScanner(strings.NewReader(resp.Body))
 	for sc.Scan() {
 		line := strings.TrimSpace(sc.Text())
 		if line == "" {
@@ -142,20 +416,92 @@ func (c *Client) serverForTLD(ctx context.Context, tld string) (string, error) {
 		}
 		// Example: "whois: whois.verisign-grs.com"
 		if strings.HasPrefix(strings.ToLower(line), "whois:") {
-			server := strings.TrimSpace(line[len("whois:"):])
+			server = strings.TrimSpace(line[len("whois:"):])
 			server = strings.Fields(server)[0]
 			if server != "" {
 				c.mu.Lock()
 				c.tldToServer[tld] = server
+				c.diskCache[tld] = serverCacheEntry{Server: server, FetchedAt: time.Now().UTC()}
 				c.mu.Unlock()
-				return server, nil
+				c.saveDiskCache()
+				return server, true, nil
 			}
 		}
 	}
 	if err := sc.Err(); err != nil {
-		return "", err
+		return "", true, err
+	}
+
+	// Remember the miss for this run so every domain on the TLD doesn't re-ask IANA.
+	c.mu.Lock()
+	c.tldToServer[tld] = ""
+	c.mu.Unlock()
+	return "", true, fmt.Errorf("%w for tld %q", ErrNoServer, tld)
+}
+
+type serverCacheEntry struct {
+	Server    string    `json:"server"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func (c *Client) cachePath() string {
+	if c.opts.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.opts.CacheDir, "whois-servers.json")
+}
+
+// loadDiskCacheLocked reads the on-disk referral cache once; c.mu must be held.
+func (c *Client) loadDiskCacheLocked() {
+	if c.diskLoaded {
+		return
+	}
+	c.diskLoaded = true
+	c.diskCache = make(map[string]serverCacheEntry)
+
+	path := c.cachePath()
+	if path == "" {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(b, &c.diskCache)
+	if c.diskCache == nil {
+		c.diskCache = make(map[string]serverCacheEntry)
+	}
+}
+
+func (c *Client) saveDiskCache() {
+	path := c.cachePath()
+	if path == "" {
+		return
+	}
+
+	c.mu.Lock()
+	b, err := json.Marshal(c.diskCache)
+	c.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	c.diskWriteMu.Lock()
+	defer c.diskWriteMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "whois-servers-*.json")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr == nil && cerr == nil {
+		_ = os.Rename(tmp.Name(), path)
+	} else {
+		_ = os.Remove(tmp.Name())
 	}
-	return "", fmt.Errorf("whois server not found for tld %q", tld)
 }
 
 func (c *Client) stateForServer(server string) *perServerState {
@@ -172,7 +518,23 @@ func (c *Client) stateForServer(server string) *perServerState {
 	return st
 }
 
-func (c *Client) query(ctx context.Context, server, q string) (string, error) {
+type response struct {
+	Body      string
+	Truncated bool
+
+	// BytesRead and Latency describe the successful exchange: bytes
+	// received, and the time from connection established to body read.
+	BytesRead int
+	Latency   time.Duration
+
+	// Attempts is how many times query sent the request (1 + retries),
+	// also set when the query ultimately fails.
+	Attempts int
+}
+
+// query sends q to server with retries. A non-empty limitKey is passed to
+// Options.Limiter before each attempt.
+func (c *Client) query(ctx context.Context, server, q, limitKey string) (response, error) {
 	attempts := c.opts.Retries + 1
 	if attempts < 1 {
 		attempts = 1
@@ -181,72 +543,163 @@ func (c *Client) query(ctx context.Context, server, q string) (string, error) {
 	if backoff <= 0 {
 		backoff = 250 * time.Millisecond
 	}
+	base := backoff
+	backoff = minDuration(backoff, c.opts.MaxBackoff)
 
 	var lastErr error
+	tried := 0
 	for attempt := 0; attempt < attempts; attempt++ {
-		body, err := c.queryOnce(ctx, server, q)
+		if limitKey != "" && c.opts.Limiter != nil {
+			if err := c.opts.Limiter.Wait(ctx, limitKey); err != nil {
+				return response{Attempts: tried}, err
+			}
+		}
+		tried++
+		resp, err := c.queryOnce(ctx, server, q)
+		if err == nil && isMaintenance(resp.Body) {
+			// A maintenance notice often says "not found" too; never
+			// classify it.
+			err = ErrMaintenance
+		}
 		if err == nil {
-			return body, nil
+			resp.Attempts = tried
+			return resp, nil
 		}
 		lastErr = err
 
 		if attempt == attempts-1 || !isRetryable(err) {
 			break
 		}
-		if err := sleepWithContext(ctx, backoff); err != nil {
-			return "", err
+		if err := c.opts.Clock.Sleep(ctx, backoff); err != nil {
+			return response{Attempts: tried}, err
 		}
-		backoff = minDuration(backoff*2, 2*time.Second)
+		backoff = c.nextBackoff(backoff, base)
+	}
+
+	return response{Attempts: tried}, lastErr
+}
+
+// BackoffStrategy selects how retry delays grow.
+type BackoffStrategy string
+
+const (
+	BackoffConstant    BackoffStrategy = "constant"
+	BackoffLinear      BackoffStrategy = "linear"
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// ParseBackoffStrategy validates a user-supplied strategy name.
+func ParseBackoffStrategy(s string) (BackoffStrategy, error) {
+	switch v := BackoffStrategy(strings.ToLower(strings.TrimSpace(s))); v {
+	case BackoffConstant, BackoffLinear, BackoffExponential:
+		return v, nil
+	case "":
+		return BackoffExponential, nil
+	default:
+		return "", fmt.Errorf("invalid backoff strategy %q (use constant|linear|exponential)", s)
 	}
+}
 
-	return "", lastErr
+// nextBackoff returns the delay after cur, capped at MaxBackoff.
+func (c *Client) nextBackoff(cur, base time.Duration) time.Duration {
+	var next time.Duration
+	switch c.opts.BackoffStrategy {
+	case BackoffConstant:
+		next = base
+	case BackoffLinear:
+		next = cur + base
+	default:
+		next = cur * 2
+	}
+	return minDuration(next, c.opts.MaxBackoff)
+}
+
+// ServerAddr turns a WHOIS server given as "host", "host:port" or an IP
+// literal into a dialable address, defaulting to port 43.
+func ServerAddr(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return "", fmt.Errorf("empty whois server")
+	}
+	if ip := net.ParseIP(server); ip != nil {
+		return net.JoinHostPort(server, "43"), nil
+	}
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		if strings.ContainsAny(server, ":[]") {
+			return "", fmt.Errorf("malformed whois server %q (use host or host:port)", server)
+		}
+		return net.JoinHostPort(server, "43"), nil
+	}
+	if host == "" {
+		return "", fmt.Errorf("malformed whois server %q: missing host", server)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid port %q in whois server %q", port, server)
+	}
+	return net.JoinHostPort(host, port), nil
 }
 
-func (c *Client) queryOnce(ctx context.Context, server, q string) (string, error) {
+func (c *Client) queryOnce(ctx context.Context, server, q string) (response, error) {
+	addr, err := ServerAddr(server)
+	if err != nil {
+		return response{}, err
+	}
 	st := c.stateForServer(server)
+	if c.skipping(st) {
+		return response{}, fmt.Errorf("%w: %s", ErrUnreachable, server)
+	}
 
 	// Bound concurrency per server.
 	select {
 	case st.sem <- struct{}{}:
 		defer func() { <-st.sem }()
 	case <-ctx.Done():
-		return "", ctx.Err()
+		return response{}, ctx.Err()
 	}
 
 	// Rate limit per server, but don't count this wait time towards the network timeout.
 	if c.opts.MinDelayPerServer > 0 {
 		st.mu.Lock()
-		scheduled := time.Now()
+		now := c.opts.Clock.Now()
+		scheduled := now
 		if scheduled.Before(st.next) {
 			scheduled = st.next
 		}
 		st.next = scheduled.Add(c.opts.MinDelayPerServer)
 		st.mu.Unlock()
-		if err := sleepUntil(ctx, scheduled); err != nil {
-			return "", err
+		if err := c.opts.Clock.Sleep(ctx, scheduled.Sub(now)); err != nil {
+			return response{}, err
 		}
 	}
 
 	attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
 	defer cancel()
 
-	conn, err := (&net.Dialer{}).DialContext(attemptCtx, "tcp", net.JoinHostPort(server, "43"))
+	conn, err := c.opts.DialFunc(attemptCtx, "tcp", addr)
+	c.noteDial(st, err)
 	if err != nil {
-		return "", err
+		return response{}, err
 	}
 	defer conn.Close()
 
 	_ = conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+	start := time.Now()
 
 	if _, err := io.WriteString(conn, q+"\r\n"); err != nil {
-		return "", err
+		return response{}, err
 	}
 
-	b, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	// Read one byte past the cap so truncation can be detected.
+	b, err := io.ReadAll(io.LimitReader(conn, c.opts.MaxBodyBytes+1))
 	if err != nil {
-		return "", err
+		return response{}, err
 	}
-	return string(b), nil
+	latency := time.Since(start)
+	if int64(len(b)) > c.opts.MaxBodyBytes {
+		return response{Body: string(b[:c.opts.MaxBodyBytes]), Truncated: true, BytesRead: len(b), Latency: latency}, nil
+	}
+	return response{Body: string(b), BytesRead: len(b), Latency: latency}, nil
 }
 
 var notFoundPatterns = []struct {
@@ -262,24 +715,66 @@ var notFoundPatterns = []struct {
 	{"not found", "not_found"},
 }
 
+// reservedPatterns mark names the registry holds back (reserved, premium
+// withheld or prohibited strings): not registered, but not registrable either.
+// They are checked before the not-found patterns because such responses often
+// also say "not found". Bare "reserved" is avoided since disclaimers commonly
+// end in "All rights reserved".
+var reservedPatterns = []struct {
+	Needle  string
+	Pattern string
+}{
+	{"reserved by the registry", "reserved_by_registry"},
+	{"reserved by registry", "reserved_by_registry"},
+	{"reserved for registry", "reserved_for_registry"},
+	{"registry reserved", "registry_reserved"},
+	{"not available for registration", "not_available_for_registration"},
+	{"prohibited string", "prohibited_string"},
+	{"status: reserved", "status_reserved"},
+}
+
+// maintenancePatterns mark a notice that the registry is down for
+// maintenance. They are phrases rather than the bare word, which shows up in
+// real records (e.g. registrar and organisation names).
+var maintenancePatterns = []string{
+	"undergoing maintenance",
+	"under maintenance",
+	"down for maintenance",
+	"scheduled maintenance",
+	"maintenance mode",
+	"maintenance window",
+	"due to maintenance",
+}
+
+func isMaintenance(body string) bool {
+	l := strings.ToLower(body)
+	for _, p := range maintenancePatterns {
+		if strings.Contains(l, p) {
+			return true
+		}
+	}
+	return false
+}
+
 func classify(domain, body string) (status string, pattern string) {
 	l := strings.ToLower(body)
+	for _, p := range reservedPatterns {
+		if strings.Contains(l, p.Needle) {
+			return "reserved", p.Pattern
+		}
+	}
 	for _, p := range notFoundPatterns {
 		if strings.Contains(l, p.Needle) {
 			return "available", p.Pattern
 		}
 	}
 
-	// Try to detect a record that explicitly names the domain.
-	escaped := regexp.QuoteMeta(domain)
-	for _, re := range []*regexp.Regexp{
-		regexp.MustCompile(`(?im)^domain name:\s*` + escaped + `\s*$`),
-		regexp.MustCompile(`(?im)^domain:\s*` + escaped + `\s*$`),
-		regexp.MustCompile(`(?im)^domain\s*:\s*` + escaped + `\s*$`),
-	} {
-		if re.FindStringIndex(body) != nil {
-			return "taken", re.String()
-		}
+	// Try to detect a record that explicitly names the domain. Servers indent
+	// the field (Verisign), put the value on the next line (Nominet), end
+	// lines with CRLF, add a trailing dot or append more fields after it.
+	re := regexp.MustCompile(`(?im)^[ \t]*domain(?:[ \t]+name)?[ \t]*:\s*` + regexp.QuoteMeta(domain) + `\.?(?:\s|$)`)
+	if re.FindStringIndex(body) != nil {
+		return "taken", re.String()
 	}
 
 	// Fallback heuristics.
@@ -290,6 +785,27 @@ func classify(domain, body string) (status string, pattern string) {
 	return "unknown", ""
 }
 
+var statusLineRe = regexp.MustCompile(`(?im)^\s*(?:domain\s+)?status\s*:\s*([A-Za-z][A-Za-z ]*?)(?:\s+https?://\S*|\s*\(.*\))?\s*$`)
+
+// parseStatuses extracts EPP status codes, e.g.
+// "Domain Status: pendingDelete https://icann.org/epp#pendingDelete".
+func parseStatuses(body string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, m := range statusLineRe.FindAllStringSubmatch(body, -1) {
+		st := strings.TrimSpace(m[1])
+		if st == "" {
+			continue
+		}
+		if _, ok := seen[st]; ok {
+			continue
+		}
+		seen[st] = struct{}{}
+		out = append(out, st)
+	}
+	return out
+}
+
 func lastLabel(domain string) string {
 	i := strings.LastIndexByte(domain, '.')
 	if i < 0 || i == len(domain)-1 {
@@ -298,23 +814,35 @@ func lastLabel(domain string) string {
 	return domain[i+1:]
 }
 
-func sleepWithContext(ctx context.Context, d time.Duration) error {
-	if d <= 0 {
-		return nil
+// skipping reports whether st is cooling down after refused connections.
+func (c *Client) skipping(st *perServerState) bool {
+	if c.opts.RefusedCooldown < 0 {
+		return false
 	}
-	t := time.NewTimer(d)
-	defer t.Stop()
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-t.C:
-		return nil
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return c.opts.Clock.Now().Before(st.skipUntil)
+}
+
+// noteDial tracks refused dials to st, starting a cooldown once
+// RefusedThreshold of them happen in a row. Any other outcome resets the
+// count: timeouts and resets are worth retrying later.
+func (c *Client) noteDial(st *perServerState, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !isConnRefused(err) {
+		st.refused = 0
+		return
+	}
+	st.refused++
+	if c.opts.RefusedCooldown > 0 && st.refused >= c.opts.RefusedThreshold {
+		st.skipUntil = c.opts.Clock.Now().Add(c.opts.RefusedCooldown)
+		st.refused = 0
 	}
 }
 
-func sleepUntil(ctx context.Context, at time.Time) error {
-	wait := time.Until(at)
-	return sleepWithContext(ctx, wait)
+func isConnRefused(err error) bool {
+	return err != nil && errors.Is(err, syscall.ECONNREFUSED)
 }
 
 func minDuration(a, b time.Duration) time.Duration {
@@ -331,6 +859,9 @@ func isRetryable(err error) bool {
 	if errors.Is(err, context.Canceled) {
 		return false
 	}
+	if errors.Is(err, ErrMaintenance) {
+		return true
+	}
 
 	// Timeouts are often transient for WHOIS.
 	if errors.Is(err, context.DeadlineExceeded) {
@@ -1,6 +1,22 @@
 package whois
 
-import "testing"
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/clock"
+)
 
 func TestClassify_Available(t *testing.T) {
 	t.Parallel()
@@ -22,3 +38,512 @@ func TestClassify_Taken(t *testing.T) {
 		t.Fatalf("status=%q, want taken", status)
 	}
 }
+
+func TestClassify_TakenRecordVariants(t *testing.T) {
+	t.Parallel()
+
+	for name, body := range map[string]string{
+		"verisign crlf indented": "   Domain Name: EXAMPLE.COM\r\n   Registry Domain ID: 2336799_DOMAIN_COM-VRSN\r\n",
+		"value on next line":     "\n    Domain name:\n        example.com\n\n    Data validation:\n",
+		"trailing dot":           "domain:       example.com.\n",
+		"extra fields":           "Domain: example.com (ACTIVE)\n",
+		"echoed query banner":    "% Query: example.com\r\nDomain Name: example.com\r\n",
+	} {
+		if status, pattern := classify("example.com", body); status != "taken" || pattern == "heuristic_record_fields" {
+			t.Fatalf("%s: status=%q pattern=%q, want taken via record match", name, status, pattern)
+		}
+	}
+
+	// A record for a longer name must not count as one for example.com.
+	if status, _ := classify("example.com", "Domain: example.com.au\n"); status != "unknown" {
+		t.Fatalf("status=%q for example.com.au record, want unknown", status)
+	}
+}
+
+func TestClassify_Reserved(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		"Domain not found.\nThis name is reserved by the Registry in accordance with ICANN Policy.\n",
+		"The domain name nic.example is not available for registration.\n",
+	} {
+		if status, _ := classify("nic.example", body); status != "reserved" {
+			t.Fatalf("classify(%q)=%q, want reserved", body, status)
+		}
+	}
+
+	// Disclaimer boilerplate must not read as reserved.
+	status, _ := classify("example.com", "No match for \"EXAMPLE.COM\".\nCopyright Example Registry. All rights reserved.\n")
+	if status != "available" {
+		t.Fatalf("status=%q, want available despite \"All rights reserved\"", status)
+	}
+}
+
+func TestClient_LookupDomain_DialFunc(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		DialFunc: fakeWHOIS(t, map[string]string{
+			"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+			"whois.example-registry.test|free.com": "No match for \"FREE.COM\".\n",
+		}),
+	})
+
+	ev := c.LookupDomain(context.Background(), "free.com")
+	if ev.Status != "available" {
+		t.Fatalf("status=%q (%v), want available", ev.Status, ev.Err)
+	}
+	if ev.Server != "whois.example-registry.test" {
+		t.Fatalf("server=%q, want whois.example-registry.test", ev.Server)
+	}
+}
+
+func TestClient_LookupDomain_MemoizeWithinRun(t *testing.T) {
+	t.Parallel()
+
+	var dials atomic.Int32
+	dial := fakeWHOIS(t, map[string]string{
+		"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+		"whois.example-registry.test|free.com": "No match for \"FREE.COM\".\n",
+	})
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		MemoizeWithinRun:  true,
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if strings.HasPrefix(addr, "whois.example-registry.test:") {
+				dials.Add(1)
+			}
+			return dial(ctx, network, addr)
+		},
+	})
+
+	for _, d := range []string{"free.com", "FREE.com", "free.com"} {
+		if ev := c.LookupDomain(context.Background(), d); ev.Status != "available" {
+			t.Fatalf("LookupDomain(%s) status=%q (%v), want available", d, ev.Status, ev.Err)
+		}
+	}
+	if got := dials.Load(); got != 1 {
+		t.Fatalf("registry queries=%d, want 1", got)
+	}
+}
+
+func TestClient_LookupDomain_MemoizeWithinRunConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var dials atomic.Int32
+	dial := fakeWHOIS(t, map[string]string{
+		"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+		"whois.example-registry.test|free.com": "No match for \"FREE.COM\".\n",
+	})
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		MemoizeWithinRun:  true,
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if strings.HasPrefix(addr, "whois.example-registry.test:") {
+				dials.Add(1)
+				// Hold the query open so the other lookups overlap it.
+				time.Sleep(50 * time.Millisecond)
+			}
+			return dial(ctx, network, addr)
+		},
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ev := c.LookupDomain(context.Background(), "free.com"); ev.Status != "available" {
+				t.Errorf("status=%q (%v), want available", ev.Status, ev.Err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := dials.Load(); got != 1 {
+		t.Fatalf("registry queries=%d, want 1", got)
+	}
+}
+
+func TestClient_LookupDomain_ServerOverridePort(t *testing.T) {
+	t.Parallel()
+
+	var dialed string
+	dial := fakeWHOIS(t, map[string]string{
+		"mirror.test|free.com.au": "No Data Found\n",
+	})
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		ServerOverrides:   map[string]string{"COM.AU": "mirror.test:4343"},
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = addr
+			return dial(ctx, network, addr)
+		},
+	})
+
+	ev := c.LookupDomain(context.Background(), "free.com.au")
+	if ev.Status != "available" {
+		t.Fatalf("status=%q (%v), want available", ev.Status, ev.Err)
+	}
+	if dialed != "mirror.test:4343" {
+		t.Fatalf("dialed=%q, want mirror.test:4343", dialed)
+	}
+}
+
+func TestServerAddr(t *testing.T) {
+	t.Parallel()
+
+	ok := map[string]string{
+		"whois.nic.test":      "whois.nic.test:43",
+		"whois.nic.test:4343": "whois.nic.test:4343",
+		"192.0.2.1":           "192.0.2.1:43",
+		"2001:db8::1":         "[2001:db8::1]:43",
+		"[2001:db8::1]:4343":  "[2001:db8::1]:4343",
+	}
+	for in, want := range ok {
+		if got, err := ServerAddr(in); err != nil || got != want {
+			t.Fatalf("ServerAddr(%q)=%q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "host:0", "host:99999", "host:abc", ":43", "host:43:1"} {
+		if got, err := ServerAddr(in); err == nil {
+			t.Fatalf("ServerAddr(%q)=%q, want error", in, got)
+		}
+	}
+}
+
+func TestClient_Query_BackoffStrategies(t *testing.T) {
+	t.Parallel()
+
+	ms := time.Millisecond
+	tests := []struct {
+		strategy BackoffStrategy
+		want     []time.Duration
+	}{
+		{BackoffConstant, []time.Duration{100 * ms, 100 * ms, 100 * ms, 100 * ms}},
+		{BackoffLinear, []time.Duration{100 * ms, 200 * ms, 300 * ms, 350 * ms}},
+		{BackoffExponential, []time.Duration{100 * ms, 200 * ms, 350 * ms, 350 * ms}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			t.Parallel()
+
+			clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewClient(Options{
+				CacheDir:          t.TempDir(),
+				MinDelayPerServer: time.Nanosecond,
+				Retries:           4,
+				Backoff:           100 * ms,
+				MaxBackoff:        350 * ms,
+				BackoffStrategy:   tt.strategy,
+				DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					return nil, errors.New("connection reset by peer")
+				},
+				Clock: clk,
+			})
+
+			if _, err := c.query(context.Background(), "whois.test", "example.com", ""); err == nil {
+				t.Fatalf("query err=nil, want dial error")
+			}
+			if got := clk.Sleeps(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("delays=%v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_LookupDomain_Attempts(t *testing.T) {
+	t.Parallel()
+
+	registryDials := 0
+	dial := fakeWHOIS(t, map[string]string{
+		"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+		"whois.example-registry.test|free.com": "No match for \"FREE.COM\".\n",
+	})
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		Retries:           3,
+		Clock:             clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if strings.HasPrefix(addr, "whois.example-registry.test:") {
+				registryDials++
+				if registryDials < 3 {
+					return nil, errors.New("connection reset by peer")
+				}
+			}
+			return dial(ctx, network, addr)
+		},
+	})
+
+	ev := c.LookupDomain(context.Background(), "free.com")
+	if ev.Status != "available" || ev.Attempts != 3 {
+		t.Fatalf("status=%q attempts=%d (%v), want available after 3 attempts", ev.Status, ev.Attempts, ev.Err)
+	}
+}
+
+func TestClient_LookupDomain_RefusedCooldown(t *testing.T) {
+	t.Parallel()
+
+	refusedDials := 0
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewClient(Options{
+		NoDiskCache:       true,
+		MinDelayPerServer: time.Nanosecond,
+		Clock:             fake,
+		RefusedCooldown:   time.Minute,
+		ServerOverrides:   map[string]string{"dev": "whois.stale.test"},
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			refusedDials++
+			return nil, &net.OpError{Op: "dial", Net: network, Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+		},
+	})
+
+	ctx := context.Background()
+	for i, name := range []string{"a.dev", "b.dev", "c.dev", "d.dev"} {
+		ev := c.LookupDomain(ctx, name)
+		if ev.Status != "unknown" || ev.Reason != "whois unreachable" {
+			t.Fatalf("%s status=%q reason=%q, want unknown/whois unreachable", name, ev.Status, ev.Reason)
+		}
+		if i >= 2 && !errors.Is(ev.Err, ErrUnreachable) {
+			t.Fatalf("%s err=%v, want ErrUnreachable once the server is skipped", name, ev.Err)
+		}
+	}
+	if refusedDials != 2 {
+		t.Fatalf("dials=%d, want 2 (skipped after the second refusal)", refusedDials)
+	}
+
+	fake.Advance(time.Minute)
+	c.LookupDomain(ctx, "e.dev")
+	if refusedDials != 3 {
+		t.Fatalf("dials=%d, want the server retried after the cooldown", refusedDials)
+	}
+}
+
+func TestParseBackoffStrategy(t *testing.T) {
+	t.Parallel()
+
+	if got, err := ParseBackoffStrategy(" Linear "); err != nil || got != BackoffLinear {
+		t.Fatalf("ParseBackoffStrategy(Linear)=%q, %v; want linear", got, err)
+	}
+	if got, err := ParseBackoffStrategy(""); err != nil || got != BackoffExponential {
+		t.Fatalf("ParseBackoffStrategy(\"\")=%q, %v; want exponential", got, err)
+	}
+	if _, err := ParseBackoffStrategy("fibonacci"); err == nil {
+		t.Fatalf("ParseBackoffStrategy(fibonacci): err=nil, want error")
+	}
+}
+
+// fakeWHOIS returns a DialFunc that answers queries from responses, keyed by
+// "host|query".
+func fakeWHOIS(t *testing.T, responses map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	t.Helper()
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			line, err := bufio.NewReader(server).ReadString('\n')
+			if err != nil {
+				return
+			}
+			q := strings.TrimSpace(line)
+			_, _ = io.WriteString(server, responses[host+"|"+q])
+		}()
+		return client, nil
+	}
+}
+
+func TestClient_ServerForTLD_DiskCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := NewClient(Options{
+		CacheDir:          dir,
+		MinDelayPerServer: time.Nanosecond,
+		DialFunc: fakeWHOIS(t, map[string]string{
+			"whois.iana.org|dev": "whois:        whois.nic.test\n",
+		}),
+	})
+	if got, err := c.ServerForTLD(context.Background(), "dev"); err != nil || got != "whois.nic.test" {
+		t.Fatalf("ServerForTLD=%q, %v; want whois.nic.test", got, err)
+	}
+
+	// A fresh client must answer from disk without dialing IANA.
+	c = NewClient(Options{
+		CacheDir: dir,
+		DialFunc: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			t.Fatalf("unexpected dial to %s", addr)
+			return nil, nil
+		},
+	})
+	if got, err := c.ServerForTLD(context.Background(), "dev"); err != nil || got != "whois.nic.test" {
+		t.Fatalf("cached ServerForTLD=%q, %v; want whois.nic.test", got, err)
+	}
+}
+
+func TestClient_LookupDomain_TruncationBoundary(t *testing.T) {
+	t.Parallel()
+
+	body := "Domain Name: example.com\nRegistrar: Example Registrar\n"
+	for _, tc := range []struct {
+		name      string
+		max       int64
+		truncated bool
+	}{
+		{name: "exactly at cap", max: int64(len(body)), truncated: false},
+		{name: "one byte over cap", max: int64(len(body)) - 1, truncated: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(Options{
+				CacheDir:          t.TempDir(),
+				MinDelayPerServer: time.Nanosecond,
+				MaxBodyBytes:      tc.max,
+				DialFunc: fakeWHOIS(t, map[string]string{
+					"whois.iana.org|com":              "whois: whois.registry.test\n",
+					"whois.registry.test|example.com": body,
+				}),
+			})
+
+			ev := c.LookupDomain(context.Background(), "example.com")
+			if ev.Truncated != tc.truncated {
+				t.Fatalf("Truncated=%v, want %v", ev.Truncated, tc.truncated)
+			}
+			if ev.Status != "taken" {
+				t.Fatalf("status=%q, want taken", ev.Status)
+			}
+			if tc.truncated && ev.Confidence != "low" {
+				t.Fatalf("Confidence=%q, want low for truncated body", ev.Confidence)
+			}
+		})
+	}
+}
+
+func TestParseStatuses(t *testing.T) {
+	t.Parallel()
+
+	body := "Domain Name: example.com\r\n" +
+		"Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited\r\n" +
+		"Domain Status: pendingDelete https://icann.org/epp#pendingDelete\r\n" +
+		"Status: redemptionPeriod\n"
+
+	got := parseStatuses(body)
+	want := "clientTransferProhibited,pendingDelete,redemptionPeriod"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("parseStatuses=%v, want %s", got, want)
+	}
+}
+
+func TestClient_DefaultDialerUsesResolver(t *testing.T) {
+	t.Parallel()
+
+	var queried atomic.Bool
+	c := NewClient(Options{
+		CacheDir: t.TempDir(),
+		Retries:  -1,
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				queried.Store(true)
+				return nil, errors.New("no dns in test")
+			},
+		},
+		FallbackDelay: 50 * time.Millisecond,
+	})
+
+	if _, err := c.query(context.Background(), "whois.registry.test", "example.com", ""); err == nil {
+		t.Fatal("query: want error from failing resolver")
+	}
+	if !queried.Load() {
+		t.Fatal("default dialer did not use Options.Resolver")
+	}
+}
+
+func TestClient_LookupDomain_Maintenance(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		Retries:           2,
+		Clock:             clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		DialFunc: fakeWHOIS(t, map[string]string{
+			"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+			"whois.example-registry.test|free.com": "The registry is currently undergoing maintenance.\nDomain not found.\n",
+		}),
+	})
+
+	ev := c.LookupDomain(context.Background(), "free.com")
+	if ev.Status != "unknown" || ev.Reason != "registry maintenance" || !errors.Is(ev.Err, ErrMaintenance) {
+		t.Fatalf("status=%q reason=%q err=%v, want unknown registry maintenance", ev.Status, ev.Reason, ev.Err)
+	}
+	if ev.Attempts != 3 {
+		t.Fatalf("attempts=%d, want 3 (maintenance is retried)", ev.Attempts)
+	}
+}
+
+func TestClient_Query_PacesPerServer(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: 250 * time.Millisecond,
+		Clock:             clk,
+		DialFunc: fakeWHOIS(t, map[string]string{
+			"a.test|one.com":   "No match\n",
+			"a.test|two.com":   "No match\n",
+			"a.test|three.com": "No match\n",
+			"b.test|one.com":   "No match\n",
+		}),
+	})
+
+	ctx := context.Background()
+	for _, q := range []struct{ server, domain string }{
+		{"a.test", "one.com"}, {"a.test", "two.com"}, {"b.test", "one.com"}, {"a.test", "three.com"},
+	} {
+		if _, err := c.query(ctx, q.server, q.domain, ""); err != nil {
+			t.Fatalf("query(%s, %s): %v", q.server, q.domain, err)
+		}
+	}
+	// a.test waits its 250ms turn each time; b.test's first query doesn't.
+	if want := "[250ms 250ms]"; fmt.Sprint(clk.Sleeps()) != want {
+		t.Fatalf("sleeps=%v, want %s", clk.Sleeps(), want)
+	}
+}
+
+func TestClient_LookupDomain_ServerChain(t *testing.T) {
+	t.Parallel()
+
+	dial := fakeWHOIS(t, map[string]string{
+		"whois.iana.org|com":                   "whois:        whois.example-registry.test\n",
+		"whois.example-registry.test|free.com": "No match for \"FREE.COM\".\n",
+		"mirror.test|free.net":                 "No match for \"FREE.NET\".\n",
+	})
+	c := NewClient(Options{
+		CacheDir:          t.TempDir(),
+		MinDelayPerServer: time.Nanosecond,
+		ServerOverrides:   map[string]string{"net": "mirror.test"},
+		DialFunc:          dial,
+	})
+
+	// The second .com lookup is answered from the referral cache but still
+	// came from IANA.
+	for range 2 {
+		ev := c.LookupDomain(context.Background(), "free.com")
+		if want := "[whois.iana.org whois.example-registry.test]"; fmt.Sprint(ev.ServerChain) != want {
+			t.Fatalf("ServerChain=%v, want %s", ev.ServerChain, want)
+		}
+	}
+	if ev := c.LookupDomain(context.Background(), "free.net"); fmt.Sprint(ev.ServerChain) != "[mirror.test]" {
+		t.Fatalf("ServerChain=%v, want [mirror.test] for an override", ev.ServerChain)
+	}
+}
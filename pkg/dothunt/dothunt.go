@@ -0,0 +1,167 @@
+// Package dothunt checks domain availability from Go code, the same way the
+// dothuntcli command does: RDAP first, WHOIS as a fallback, with a
+// conservative verdict (available only on an authoritative not-found).
+//
+//	c := dothunt.New(dothunt.Options{})
+//	r, err := c.Check(ctx, "example.com")
+//
+// A Checker is safe for concurrent use and should be reused: it caches the
+// RDAP bootstrap and WHOIS server referrals and paces requests per server.
+package dothunt
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+	"github.com/benithors/dothuntcli/internal/domain"
+	"github.com/benithors/dothuntcli/internal/rdap"
+	"github.com/benithors/dothuntcli/internal/whois"
+)
+
+// Status is a Result's verdict.
+type Status string
+
+const (
+	StatusAvailable Status = "available"
+	StatusTaken     Status = "taken"
+	StatusReserved  Status = "reserved"
+	StatusUnknown   Status = "unknown"
+)
+
+// Result is one domain's verdict with per-method diagnostics. Fields carry
+// the same JSON names as the CLI's NDJSON record; CLI-only fields (registrar
+// pricing, scoring) are left out.
+type Result struct {
+	Input      string `json:"input,omitempty"`
+	Domain     string `json:"domain"`
+	Label      string `json:"label,omitempty"`
+	TLD        string `json:"tld,omitempty"`
+	Status     Status `json:"status"`
+	Registered *bool  `json:"registered,omitempty"`
+	// Method is the lookup that decided the verdict: rdap, whois or none.
+	Method     string `json:"method"`
+	Confidence string `json:"confidence"`
+	Detail     string `json:"detail,omitempty"`
+	Conflict   string `json:"conflict,omitempty"`
+	// Warnings are caveats about the input or the verdict, one sentence each.
+	Warnings []string `json:"warnings,omitempty"`
+	// DomainStatus holds the registry status codes, when returned.
+	DomainStatus []string `json:"domain_status,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	CheckedAt    string   `json:"checked_at"`
+	DurationMs   int64    `json:"duration_ms"`
+
+	RDAPStatus string `json:"rdap_status,omitempty"`
+	RDAPReason string `json:"rdap_reason,omitempty"`
+	RDAPError  string `json:"rdap_error,omitempty"`
+	RDAPURL    string `json:"rdap_url,omitempty"`
+	RDAPCode   int    `json:"rdap_http_status,omitempty"`
+
+	WHOISStatus string `json:"whois_status,omitempty"`
+	WHOISReason string `json:"whois_reason,omitempty"`
+	WHOISError  string `json:"whois_error,omitempty"`
+	WHOISServer string `json:"whois_server,omitempty"`
+}
+
+// fromResult copies the public fields out of an internal result, so changes
+// to the CLI's record don't leak into this API.
+func fromResult(r availability.Result) Result {
+	return Result{
+		Input:        r.Input,
+		Domain:       r.Domain,
+		Label:        r.Label,
+		TLD:          r.TLD,
+		Status:       Status(r.Status),
+		Registered:   r.Registered,
+		Method:       string(r.Method),
+		Confidence:   r.Confidence,
+		Detail:       r.Detail,
+		Conflict:     r.Conflict,
+		Warnings:     slices.Clone(r.Warnings),
+		DomainStatus: slices.Clone(r.DomainStatus),
+		Error:        r.Error,
+		CheckedAt:    r.CheckedAt,
+		DurationMs:   r.DurationMs,
+		RDAPStatus:   r.RDAPStatus,
+		RDAPReason:   r.RDAPReason,
+		RDAPError:    r.RDAPError,
+		RDAPURL:      r.RDAPURL,
+		RDAPCode:     r.RDAPCode,
+		WHOISStatus:  r.WHOISStatus,
+		WHOISReason:  r.WHOISReason,
+		WHOISError:   r.WHOISError,
+		WHOISServer:  r.WHOISServer,
+	}
+}
+
+// Options configures a Checker. The zero value is ready to use.
+type Options struct {
+	// Timeout bounds each RDAP request and WHOIS query (default 8s).
+	Timeout time.Duration
+
+	// Concurrency caps parallel lookups in CheckMany (default 16).
+	Concurrency int
+
+	// NoWHOIS disables the WHOIS fallback, leaving RDAP-less TLDs unknown.
+	NoWHOIS bool
+
+	// CacheDir holds the RDAP bootstrap and WHOIS referral caches (default
+	// the user cache dir's dothuntcli folder, shared with the CLI).
+	CacheDir string
+
+	// HTTPClient, if set, is used for RDAP requests.
+	HTTPClient *http.Client
+}
+
+// Checker runs availability checks.
+type Checker struct {
+	checker *availability.Checker
+}
+
+// New returns a Checker using RDAP with a WHOIS fallback.
+func New(opts Options) *Checker {
+	return &Checker{checker: availability.NewChecker(availability.Options{
+		RDAP: rdap.NewClient(rdap.Options{
+			Timeout:    opts.Timeout,
+			CacheDir:   opts.CacheDir,
+			HTTPClient: opts.HTTPClient,
+		}),
+		WHOIS: whois.NewClient(whois.Options{
+			Timeout:  opts.Timeout,
+			CacheDir: opts.CacheDir,
+		}),
+		NoWHOIS:     opts.NoWHOIS,
+		Timeout:     opts.Timeout,
+		Concurrency: opts.Concurrency,
+		Quiet:       true,
+	})}
+}
+
+// Check looks up one domain. Input is normalized like the CLI's (URLs, ports
+// and Unicode names are accepted); invalid input or a done ctx is an error.
+// Lookup failures are not errors: they come back as StatusUnknown with the
+// reasons in the Result.
+func (c *Checker) Check(ctx context.Context, name string) (Result, error) {
+	if _, err := domain.Normalize(name); err != nil {
+		return Result{}, err
+	}
+	r := fromResult(c.checker.CheckDomains(ctx, []string{name})[0])
+	if err := ctx.Err(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// CheckMany looks up names concurrently and returns results in input order.
+// Invalid inputs yield a StatusUnknown result with Error set.
+func (c *Checker) CheckMany(ctx context.Context, names []string) []Result {
+	results := c.checker.CheckDomains(ctx, names)
+	out := make([]Result, len(results))
+	for i, r := range results {
+		out[i] = fromResult(r)
+	}
+	return out
+}
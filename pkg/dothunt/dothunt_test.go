@@ -0,0 +1,56 @@
+package dothunt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benithors/dothuntcli/internal/availability"
+)
+
+func TestCheck_InvalidInput(t *testing.T) {
+	t.Parallel()
+
+	c := New(Options{NoWHOIS: true, CacheDir: t.TempDir()})
+	if _, err := c.Check(context.Background(), "bad..name"); err == nil {
+		t.Fatal("Check(bad..name): want error")
+	}
+}
+
+func TestCheck_CanceledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := New(Options{CacheDir: t.TempDir()})
+	r, err := c.Check(ctx, "example.com")
+	if err == nil {
+		t.Fatal("Check with canceled ctx: want error")
+	}
+	if r.Domain != "example.com" || r.Status != StatusUnknown {
+		t.Fatalf("Domain=%q Status=%q, want example.com unknown", r.Domain, r.Status)
+	}
+}
+
+func TestFromResult(t *testing.T) {
+	t.Parallel()
+
+	in := availability.Result{
+		Domain:      "example.com",
+		Status:      availability.StatusTaken,
+		Method:      availability.MethodRDAP,
+		Confidence:  "high",
+		Warnings:    []string{"w"},
+		RDAPStatus:  "taken",
+		WHOISServer: "whois.example",
+		Price:       "9.99",
+	}
+	got := fromResult(in)
+	if got.Domain != "example.com" || got.Status != StatusTaken || got.Method != "rdap" || got.RDAPStatus != "taken" || got.WHOISServer != "whois.example" {
+		t.Fatalf("fromResult=%+v, want fields copied", got)
+	}
+	in.Warnings[0] = "changed"
+	if got.Warnings[0] != "w" {
+		t.Fatalf("Warnings=%v, want a copy", got.Warnings)
+	}
+}
@@ -0,0 +1,27 @@
+package dothunt_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/benithors/dothuntcli/pkg/dothunt"
+)
+
+func Example() {
+	c := dothunt.New(dothunt.Options{Timeout: 5 * time.Second})
+
+	r, err := c.Check(context.Background(), "example.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch r.Status {
+	case dothunt.StatusAvailable:
+		fmt.Println(r.Domain, "is available")
+	case dothunt.StatusUnknown:
+		fmt.Println(r.Domain, "could not be checked:", r.Detail)
+	default:
+		fmt.Println(r.Domain, "is", r.Status)
+	}
+}